@@ -2,46 +2,326 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/gommon/log"
 	"github.com/playwright-community/playwright-go"
 
 	"github.com/vitortenor/sheet-bot/internal/client"
 	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
 	"github.com/vitortenor/sheet-bot/internal/services"
 )
 
+const (
+	botMode       = "bot"
+	replMode      = "repl"
+	exportMode    = "export"
+	importMode    = "import"
+	reconcileMode = "reconcile"
+
+	sheetsBackend = "sheets"
+	sqliteBackend = "sqlite"
+)
+
 func main() {
-	log.SetOutput(&configuration.LogInterceptor{Writer: os.Stdout})
+	mode := botMode
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+
+	logWriter := os.Stdout
+	if mode != botMode {
+		// keep stdout for the conversation, the csv or the report
+		logWriter = os.Stderr
+	}
+	log.SetOutput(&configuration.LogInterceptor{Writer: logWriter})
 	log.Info("starting application...")
 
 	ctx := context.Background()
 
-	err := playwright.Install()
+	appConfig, err := configuration.InitConfig(ctx, "application.yaml")
 	if err != nil {
-		log.Error("failed to install playwright: ", err)
+		log.Fatal("failed to load configuration: ", err)
 	}
 
-	appConfig, err := configuration.InitConfig(ctx, "application.yaml")
+	ers, err := services.NewExchangeRateService(appConfig)
 	if err != nil {
-		log.Fatal("failed to load configuration: ", err)
+		log.Fatal("failed to load exchange rates: ", err)
+	}
+
+	mis := services.NewMessageInterpreterService()
+
+	var chats []boundChat
+	for _, binding := range appConfig.ChatBindings() {
+		ledger, ms := buildMessageService(ctx, appConfig.ForBinding(binding), ers, mis)
+		chats = append(chats, boundChat{
+			chatID:          binding.GroupName,
+			telegramChatIds: binding.TelegramChatIds,
+			ledger:          ledger,
+			messageService:  ms,
+		})
 	}
 
-	googleSrv, err := configuration.BuildGoogleSrv(ctx, appConfig)
+	switch mode {
+	case botMode:
+		runBot(ctx, appConfig, ers, chats)
+	case replMode:
+		ts := services.NewTerminalService(os.Stdin, os.Stdout)
+		services.NewBotService(ctx, appConfig, chats[0].messageService, ts).Run()
+	case exportMode:
+		if err := runExport(chats, os.Args[2:]); err != nil {
+			log.Fatal("failed to export transactions: ", err)
+		}
+	case importMode:
+		if err := runImport(appConfig, chats, os.Args[2:]); err != nil {
+			log.Fatal("failed to import statement: ", err)
+		}
+	case reconcileMode:
+		if err := runReconcile(appConfig, chats, os.Args[2:]); err != nil {
+			log.Fatal("failed to reconcile statement: ", err)
+		}
+	default:
+		log.Fatalf("unknown run mode %q, expected one of %q", mode, []string{botMode, replMode, exportMode, importMode, reconcileMode})
+	}
+}
+
+// boundChat is a chat with the ledger and message service of its spreadsheet
+type boundChat struct {
+	chatID          string
+	telegramChatIds []int64
+	ledger          services.Ledger
+	messageService  *services.MessageService
+}
+
+func buildMessageService(ctx context.Context, appConfig *configuration.ApplicationConfig, ers *services.ExchangeRateService,
+	mis *services.MessageInterpreterService) (services.Ledger, *services.MessageService) {
+	ledger, err := buildLedger(ctx, appConfig)
 	if err != nil {
-		log.Fatal("failed to build Google service: ", err)
+		log.Fatal("failed to build ledger: ", err)
 	}
 
-	oac := client.NewOllamaAIClient(appConfig.Ai.ModelURL)
+	oac := client.NewOllamaAIClient(appConfig.Ai.ModelURL, time.Duration(appConfig.Ai.Timeout)*time.Second, appConfig.Ai.MaxRetries)
 	oas := services.NewOllamaAIService(appConfig, oac)
 
-	gss := client.NewGoogleSheetsClient(googleSrv)
-	gsc := services.NewGoogleSheetsService(appConfig, gss)
-	mis := services.NewMessageInterpreterService()
-	ms := services.NewMessageService(ctx, appConfig, gsc, oas, mis)
+	bgs, err := services.NewBudgetService(appConfig)
+	if err != nil {
+		log.Fatal("failed to load budgets: ", err)
+	}
+
+	rs, err := services.NewRecurringService(appConfig)
+	if err != nil {
+		log.Fatal("failed to load recurring transactions: ", err)
+	}
+
+	return ledger, services.NewMessageService(ctx, appConfig, ledger, oas, mis, bgs, rs, ers)
+}
+
+func buildLedger(ctx context.Context, appConfig *configuration.ApplicationConfig) (services.Ledger, error) {
+	switch appConfig.Storage.Backend {
+	case "", sheetsBackend:
+		googleSrv, err := configuration.BuildGoogleSrv(ctx, appConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build Google service: %w", err)
+		}
+		gsc := client.NewGoogleSheetsClient(googleSrv)
+		return services.NewGoogleSheetsService(appConfig, gsc), nil
+	case sqliteBackend:
+		db, err := configuration.BuildSQLiteDB(ctx, appConfig.Storage.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+		}
+		return services.NewSQLiteLedgerService(db), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", appConfig.Storage.Backend)
+	}
+}
+
+// runBot serves every bound chat, telegram chats and the http api use the first binding unless they are bound to another
+func runBot(ctx context.Context, appConfig *configuration.ApplicationConfig, ers *services.ExchangeRateService, chats []boundChat) {
+	var transports []services.Transport
+	if appConfig.WhatsApp.IsEnabled {
+		if err := playwright.Install(); err != nil {
+			log.Error("failed to install playwright: ", err)
+		}
+		transports = append(transports, services.NewWhatsAppCrawlerService(ctx, appConfig))
+	}
+	if appConfig.Telegram.IsEnabled {
+		pollTimeout := time.Duration(appConfig.Telegram.PollTimeout) * time.Second
+		tc := client.NewTelegramClient(appConfig.Telegram.BaseURL, appConfig.Telegram.Token, pollTimeout)
+		transports = append(transports, services.NewTelegramPollingService(appConfig, tc))
+	}
+	if appConfig.Http.IsEnabled {
+		group := appConfig.Http.GroupName
+		if group == "" {
+			group = chats[0].chatID
+		}
+		ledger, err := ledgerFor(chats, group)
+		if err != nil {
+			log.Fatal("failed to start the http api: ", err)
+		}
+		ras := services.NewRestApiService(ctx, appConfig, ledger, ers)
+		if len(transports) == 0 {
+			log.Fatal("http api stopped: ", ras.Run())
+		}
+		go func() {
+			log.Fatal("http api stopped: ", ras.Run())
+		}()
+	}
+	if len(transports) == 0 {
+		log.Fatal("no transport enabled in configuration")
+	}
+
+	bs := services.NewBotService(ctx, appConfig, chats[0].messageService, transports...)
+	bs.EnableSchedules()
+	for _, chat := range chats {
+		bs.Bind(chat.chatID, chat.messageService)
+		for _, chatId := range chat.telegramChatIds {
+			bs.Bind(strconv.FormatInt(chatId, 10), chat.messageService)
+		}
+	}
+	bs.Run()
+}
+
+// runExport writes the transactions of a period to a csv, e.g. "sheet-bot export --from 2025-01-01 --to 2025-12-31 --out 2025.csv"
+func runExport(chats []boundChat, args []string) error {
+	now := time.Now()
+	flags := flag.NewFlagSet(exportMode, flag.ContinueOnError)
+	from := flags.String("from", fmt.Sprintf("%d-01-01", now.Year()), "first day to export, yyyy-mm-dd")
+	to := flags.String("to", now.Format(time.DateOnly), "last day to export, yyyy-mm-dd")
+	out := flags.String("out", "", "csv file to write, stdout when empty")
+	group := flags.String("group", chats[0].chatID, "bound group to export")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fromDay, err := time.ParseInLocation(time.DateOnly, *from, now.Location())
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	toDay, err := time.ParseInLocation(time.DateOnly, *to, now.Location())
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	ledger, err := ledgerFor(chats, *group)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	count, err := services.NewExportService(ledger).WriteCSV(w, fromDay, toDay)
+	if err != nil {
+		return err
+	}
+	log.Infof("exported %d transactions", count)
+	return nil
+}
 
-	wcs := services.NewWhatsAppCrawlerService(ctx, appConfig, ms)
-	wcs.WhatsAppCrawler()
+// runImport records the lines of a bank statement not yet in the sheet, e.g. "sheet-bot import --file fatura.csv --profile nubank"
+func runImport(appConfig *configuration.ApplicationConfig, chats []boundChat, args []string) error {
+	flags := flag.NewFlagSet(importMode, flag.ContinueOnError)
+	file := flags.String("file", "", "csv or ofx statement to import")
+	profileName := flags.String("profile", "", "csv column mapping from import.csv_profiles, e.g. nubank or itau")
+	group := flags.String("group", chats[0].chatID, "bound group to import into")
+	dryRun := flags.Bool("dry-run", false, "print the transactions instead of recording them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ledger, err := ledgerFor(chats, *group)
+	if err != nil {
+		return err
+	}
+
+	is := services.NewImportService(ledger)
+	transactions, err := parseStatement(appConfig, is, *file, *profileName)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, transaction := range transactions {
+			fmt.Printf("%.2f / %s %s\n", transaction.Amount, transaction.Description, transaction.Date.Format("02/01/2006"))
+		}
+		return nil
+	}
+
+	imported, skipped, err := is.Import(transactions)
+	log.Infof("imported %d transactions, skipped %d already recorded or invalid", imported, skipped)
+	return err
+}
 
+// runReconcile prints which statement lines were logged through the bot and which are missing on either side,
+// e.g. "sheet-bot reconcile --file fatura.csv --profile nubank"
+func runReconcile(appConfig *configuration.ApplicationConfig, chats []boundChat, args []string) error {
+	flags := flag.NewFlagSet(reconcileMode, flag.ContinueOnError)
+	file := flags.String("file", "", "csv or ofx statement to reconcile")
+	profileName := flags.String("profile", "", "csv column mapping from import.csv_profiles, e.g. nubank or itau")
+	group := flags.String("group", chats[0].chatID, "bound group to reconcile against")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ledger, err := ledgerFor(chats, *group)
+	if err != nil {
+		return err
+	}
+
+	is := services.NewImportService(ledger)
+	statement, err := parseStatement(appConfig, is, *file, *profileName)
+	if err != nil {
+		return err
+	}
+
+	reconciliation, err := is.Reconcile(statement)
+	if err != nil {
+		return err
+	}
+	fmt.Print(services.ReconciliationReport(reconciliation))
+	return nil
+}
+
+// parseStatement reads an OFX file, or a CSV with the named profile
+func parseStatement(appConfig *configuration.ApplicationConfig, is *services.ImportService, file, profileName string) ([]*domain.Transaction, error) {
+	statement, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	if strings.EqualFold(filepath.Ext(file), ".ofx") {
+		return is.ParseOFX(statement)
+	}
+
+	profile, ok := appConfig.Import.CsvProfiles[profileName]
+	if !ok {
+		return nil, fmt.Errorf("unknown csv profile %q", profileName)
+	}
+	return is.ParseCSV(statement, profile)
+}
+
+func ledgerFor(chats []boundChat, group string) (services.Ledger, error) {
+	for _, chat := range chats {
+		if chat.chatID == group {
+			return chat.ledger, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown group %q", group)
 }
@@ -47,9 +47,21 @@ fi
 
 # domain tests
 DOMAIN_TEST_PATH="${PREFIX}internal/domain"
+# client tests
+CLIENT_TEST_PATH="${PREFIX}internal/client"
+# configuration tests
+CONFIG_TEST_PATH="${PREFIX}internal/configuration"
+# utils tests
+UTILS_TEST_PATH="${PREFIX}internal/utils"
+# services tests
+SERVICES_TEST_PATH="${PREFIX}internal/services"
 
 # run all tests
 echo -e "${YELLOW}Running all Go tests in environment: $ENVIRONMENT...${NC}"
 run_tests "$DOMAIN_TEST_PATH"
+run_tests "$CLIENT_TEST_PATH"
+run_tests "$CONFIG_TEST_PATH"
+run_tests "$UTILS_TEST_PATH"
+run_tests "$SERVICES_TEST_PATH"
 
 echo -e "${GREEN}All tests passed successfully.${NC}"
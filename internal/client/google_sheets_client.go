@@ -32,6 +32,28 @@ func (gsc *GoogleSheetsClient) GetNote(spreadsheetId string, sheetId int64, rowA
 	return "", errors.New("note not found")
 }
 
+// GetNotes returns the notes of every row in a single column range, empty rows included
+func (gsc *GoogleSheetsClient) GetNotes(spreadsheetId string, sheetId int64, rowAndColumnRange string) ([]string, error) {
+	resp, err := gsc.srv.Spreadsheets.Get(spreadsheetId).Ranges(rowAndColumnRange).IncludeGridData(true).Do()
+	if err != nil {
+		return nil, err
+	}
+	for _, sheet := range resp.Sheets {
+		if sheet.Properties.SheetId == sheetId && len(sheet.Data) > 0 {
+			var notes []string
+			for _, rowData := range sheet.Data[0].RowData {
+				note := ""
+				if len(rowData.Values) > 0 {
+					note = rowData.Values[0].Note
+				}
+				notes = append(notes, note)
+			}
+			return notes, nil
+		}
+	}
+	return nil, errors.New("notes not found")
+}
+
 func (gsc *GoogleSheetsClient) GetValue(spreadsheetId, rowAndColumnRange string) (*sheets.ValueRange, error) {
 	return gsc.srv.Spreadsheets.Values.Get(spreadsheetId, rowAndColumnRange).Do()
 }
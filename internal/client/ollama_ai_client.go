@@ -2,53 +2,117 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
 )
 
+const defaultOllamaTimeout = 30 * time.Second
+
 type OllamaAIClient struct {
-	URL string
+	URL        string
+	httpClient *http.Client
+	maxRetries int
+	// retryBackoff is multiplied by the attempt number between retries
+	retryBackoff time.Duration
+}
+
+// retryableError marks failures worth another attempt, like a refused connection or a busy model
+type retryableError struct {
+	err error
+}
+
+func (re *retryableError) Error() string {
+	return re.err.Error()
+}
+
+func (re *retryableError) Unwrap() error {
+	return re.err
 }
 
-func NewOllamaAIClient(baseURL string) *OllamaAIClient {
+func NewOllamaAIClient(baseURL string, timeout time.Duration, maxRetries int) *OllamaAIClient {
+	if timeout <= 0 {
+		timeout = defaultOllamaTimeout
+	}
 	return &OllamaAIClient{
-		URL: baseURL,
+		URL:          baseURL,
+		httpClient:   &http.Client{Timeout: timeout},
+		maxRetries:   max(maxRetries, 0),
+		retryBackoff: 500 * time.Millisecond,
 	}
 }
 
-func (oac *OllamaAIClient) GetOllamaAIResponse(model, prompt string) (string, error) {
-	requestBody, err := json.Marshal(map[string]interface{}{
+// GetOllamaAIResponse generates a completion, a non nil format is the JSON schema the answer must follow.
+// Network errors and 5xx answers are retried up to maxRetries times, a timed out attempt is not retried.
+func (oac *OllamaAIClient) GetOllamaAIResponse(ctx context.Context, model, prompt string, format interface{}) (string, error) {
+	request := map[string]interface{}{
 		"model":  model,
 		"prompt": prompt,
 		"stream": false,
-	})
+	}
+	if format != nil {
+		request["format"] = format
+	}
+
+	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", oac.URL, bytes.NewBuffer(requestBody))
+	for attempt := 0; ; attempt++ {
+		response, err := oac.generate(ctx, requestBody)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= oac.maxRetries {
+			return response, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(oac.retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func (oac *OllamaAIClient) generate(ctx context.Context, requestBody []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", oac.URL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := oac.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
+		// a cancelled context is the caller giving up, not the model failing
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		// a hung model would keep the message waiting for another full timeout on every retry
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", fmt.Errorf("failed to send request: %w", err)
+		}
+		return "", &retryableError{err: fmt.Errorf("failed to send request: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to generate text: %s, response: %s", resp.Status, string(bodyBytes))
+		err := fmt.Errorf("failed to generate text: %s, response: %s", resp.Status, string(bodyBytes))
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return "", &retryableError{err: err}
+		}
+		return "", err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return "", &retryableError{err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 
 	return string(body), nil
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeOllamaServer also returns how many requests the server received
+func newFakeOllamaServer(t *testing.T, handler func(attempt int, w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	attempts := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(int(attempts.Add(1)), w)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, attempts
+}
+
+func TestOllamaAIClient_GetOllamaAIResponse(t *testing.T) {
+
+	_ = t.Run("retries a server error", func(t *testing.T) {
+		// arrange
+		server, attempts := newFakeOllamaServer(t, func(attempt int, w http.ResponseWriter) {
+			if attempt == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"response":"ok"}`))
+		})
+		client := NewOllamaAIClient(server.URL, time.Second, 2)
+		client.retryBackoff = time.Millisecond
+
+		// act
+		response, err := client.GetOllamaAIResponse(context.Background(), "llama3.2", "oi", nil)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, `{"response":"ok"}`, response)
+		_ = assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	_ = t.Run("gives up after the retries", func(t *testing.T) {
+		// arrange
+		server, attempts := newFakeOllamaServer(t, func(attempt int, w http.ResponseWriter) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		client := NewOllamaAIClient(server.URL, time.Second, 2)
+		client.retryBackoff = time.Millisecond
+
+		// act
+		_, err := client.GetOllamaAIResponse(context.Background(), "llama3.2", "oi", nil)
+
+		// assert
+		_ = assert.Error(t, err)
+		_ = assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	_ = t.Run("does not retry a bad request", func(t *testing.T) {
+		// arrange
+		server, attempts := newFakeOllamaServer(t, func(attempt int, w http.ResponseWriter) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		client := NewOllamaAIClient(server.URL, time.Second, 2)
+		client.retryBackoff = time.Millisecond
+
+		// act
+		_, err := client.GetOllamaAIResponse(context.Background(), "llama3.2", "oi", nil)
+
+		// assert
+		_ = assert.ErrorContains(t, err, "400")
+		_ = assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	_ = t.Run("does not retry a timeout", func(t *testing.T) {
+		// arrange
+		server, attempts := newFakeOllamaServer(t, func(attempt int, w http.ResponseWriter) {
+			time.Sleep(200 * time.Millisecond)
+		})
+		client := NewOllamaAIClient(server.URL, 20*time.Millisecond, 2)
+		client.retryBackoff = time.Millisecond
+
+		// act
+		_, err := client.GetOllamaAIResponse(context.Background(), "llama3.2", "oi", nil)
+
+		// assert
+		_ = assert.Error(t, err)
+		_ = assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	_ = t.Run("stops when the context is done", func(t *testing.T) {
+		// arrange
+		server, _ := newFakeOllamaServer(t, func(attempt int, w http.ResponseWriter) {
+			time.Sleep(200 * time.Millisecond)
+		})
+		client := NewOllamaAIClient(server.URL, time.Second, 2)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// act
+		_, err := client.GetOllamaAIResponse(ctx, "llama3.2", "oi", nil)
+
+		// assert
+		_ = assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type TelegramClient struct {
+	URL        string
+	httpClient *http.Client
+}
+
+type TelegramUpdate struct {
+	UpdateId int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+type TelegramMessage struct {
+	MessageId int64  `json:"message_id"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text"`
+	Chat      struct {
+		Id int64 `json:"id"`
+	} `json:"chat"`
+	From *struct {
+		FirstName string `json:"first_name"`
+		Username  string `json:"username"`
+	} `json:"from"`
+}
+
+type telegramResponse struct {
+	Ok          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+func NewTelegramClient(baseURL, token string, pollTimeout time.Duration) *TelegramClient {
+	return &TelegramClient{
+		URL: fmt.Sprintf("%s/bot%s", baseURL, token),
+		// the long polling request must be allowed to outlive the poll timeout
+		httpClient: &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+func (tc *TelegramClient) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	var updates []TelegramUpdate
+	err := tc.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	if err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+func (tc *TelegramClient) SendMessage(ctx context.Context, chatId int64, text string, replyToMessageId int64) error {
+	body := map[string]interface{}{
+		"chat_id": chatId,
+		"text":    text,
+	}
+	if replyToMessageId != 0 {
+		body["reply_to_message_id"] = replyToMessageId
+	}
+	return tc.call(ctx, "sendMessage", body, nil)
+}
+
+func (tc *TelegramClient) call(ctx context.Context, method string, body map[string]interface{}, result interface{}) error {
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tc.URL+"/"+method, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := tc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	var r telegramResponse
+	if err := json.Unmarshal(bodyBytes, &r); err != nil {
+		return fmt.Errorf("failed to decode response: %s, response: %s", resp.Status, string(bodyBytes))
+	}
+	if !r.Ok {
+		return fmt.Errorf("failed to call %s: %s", method, r.Description)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, result)
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeTelegramServer(t *testing.T, handler func(method string, body map[string]interface{}) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		method := r.URL.Path[len("/bottoken/"):]
+		_, _ = w.Write([]byte(handler(method, body)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTelegramClient_GetUpdates(t *testing.T) {
+
+	_ = t.Run("returns updates", func(t *testing.T) {
+		// arrange
+		var received map[string]interface{}
+		server := newFakeTelegramServer(t, func(method string, body map[string]interface{}) string {
+			received = body
+			return `{"ok":true,"result":[{"update_id":7,"message":{"message_id":3,"date":1700000000,"text":"-30 / cerveja","chat":{"id":42},"from":{"first_name":"Ana"}}}]}`
+		})
+		client := NewTelegramClient(server.URL, "token", time.Second)
+
+		// act
+		updates, err := client.GetUpdates(context.Background(), 5, time.Second)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, float64(5), received["offset"])
+		_ = assert.Len(t, updates, 1)
+		_ = assert.Equal(t, int64(7), updates[0].UpdateId)
+		_ = assert.Equal(t, "-30 / cerveja", updates[0].Message.Text)
+		_ = assert.Equal(t, int64(42), updates[0].Message.Chat.Id)
+		_ = assert.Equal(t, "Ana", updates[0].Message.From.FirstName)
+	})
+
+	_ = t.Run("api error", func(t *testing.T) {
+		// arrange
+		server := newFakeTelegramServer(t, func(method string, body map[string]interface{}) string {
+			return `{"ok":false,"description":"Unauthorized"}`
+		})
+		client := NewTelegramClient(server.URL, "token", time.Second)
+
+		// act
+		_, err := client.GetUpdates(context.Background(), 0, time.Second)
+
+		// assert
+		_ = assert.ErrorContains(t, err, "Unauthorized")
+	})
+}
+
+func TestTelegramClient_SendMessage(t *testing.T) {
+
+	_ = t.Run("sends reply", func(t *testing.T) {
+		// arrange
+		var method string
+		var received map[string]interface{}
+		server := newFakeTelegramServer(t, func(m string, body map[string]interface{}) string {
+			method, received = m, body
+			return `{"ok":true,"result":{}}`
+		})
+		client := NewTelegramClient(server.URL, "token", time.Second)
+
+		// act
+		err := client.SendMessage(context.Background(), 42, "sys: processed", 3)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "sendMessage", method)
+		_ = assert.Equal(t, float64(42), received["chat_id"])
+		_ = assert.Equal(t, "sys: processed", received["text"])
+		_ = assert.Equal(t, float64(3), received["reply_to_message_id"])
+	})
+}
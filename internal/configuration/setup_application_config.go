@@ -17,11 +17,69 @@ type ApplicationConfig struct {
 		ApiUrl      string `yaml:"api_url"`
 		SheetId     string `yaml:"sheet_id"`
 	} `yaml:"google"`
+	Layout   Layout `yaml:"layout"`
 	WhatsApp struct {
+		IsEnabled  bool   `yaml:"is_enabled"`
 		WebURL     string `yaml:"web_url"`
 		GroupName  string `yaml:"group_name"`
 		IsArchived bool   `yaml:"is_archived"`
 	} `yaml:"whatsapp"`
+	// Bindings route each WhatsApp group to its own spreadsheet, overriding group_name and sheet_id
+	Bindings []Binding `yaml:"bindings"`
+	Telegram struct {
+		IsEnabled      bool    `yaml:"is_enabled"`
+		Token          string  `yaml:"token"`
+		BaseURL        string  `yaml:"base_url"`
+		AllowedChatIds []int64 `yaml:"allowed_chat_ids"`
+		PollTimeout    int     `yaml:"poll_timeout"`
+	} `yaml:"telegram"`
+	Http struct {
+		IsEnabled bool   `yaml:"is_enabled"`
+		Address   string `yaml:"address"`
+		Token     string `yaml:"token"`
+		// GroupName is the binding the api records in, required when several groups are bound
+		GroupName string `yaml:"group_name"`
+	} `yaml:"http"`
+	Budgets struct {
+		FilePath string             `yaml:"file_path"`
+		Limits   map[string]float64 `yaml:"limits"`
+	} `yaml:"budgets"`
+	Currency struct {
+		FilePath string             `yaml:"file_path"`
+		Rates    map[string]float64 `yaml:"rates"`
+	} `yaml:"currency"`
+	Recurring struct {
+		FilePath string `yaml:"file_path"`
+		Hour     int    `yaml:"hour"`
+	} `yaml:"recurring"`
+	Digests struct {
+		Weekly struct {
+			IsEnabled bool `yaml:"is_enabled"`
+			Weekday   int  `yaml:"weekday"` // 0 is sunday
+			Hour      int  `yaml:"hour"`
+			Minute    int  `yaml:"minute"`
+		} `yaml:"weekly"`
+		// the monthly digest is posted on the first day of the month about the previous one
+		Monthly struct {
+			IsEnabled bool `yaml:"is_enabled"`
+			Hour      int  `yaml:"hour"`
+			Minute    int  `yaml:"minute"`
+		} `yaml:"monthly"`
+	} `yaml:"digests"`
+	Charts struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"charts"`
+	Exports struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"exports"`
+	Import struct {
+		// CsvProfiles are picked by name when importing a csv statement, they extend the default ones
+		CsvProfiles map[string]CsvProfile `yaml:"csv_profiles"`
+	} `yaml:"import"`
+	Storage struct {
+		Backend    string `yaml:"backend"`
+		SQLitePath string `yaml:"sqlite_path"`
+	} `yaml:"storage"`
 	Crawler struct {
 		UserDataDir string `yaml:"user_data_dir"`
 	} `yaml:"crawler"`
@@ -29,6 +87,12 @@ type ApplicationConfig struct {
 		IsEnabled bool   `yaml:"is_enabled"`
 		ModelURL  string `yaml:"model_url"`
 		ModelName string `yaml:"model_name"`
+		Timeout   int    `yaml:"timeout"`
+		// MaxRetries bounds the new attempts after a network error or a 5xx answer
+		MaxRetries int `yaml:"max_retries"`
+		// after FailureThreshold failed requests in a row the AI is bypassed for Cooldown seconds
+		FailureThreshold int `yaml:"failure_threshold"`
+		Cooldown         int `yaml:"cooldown"`
 	} `yaml:"ai"`
 }
 
@@ -47,12 +111,25 @@ func InitConfig(_ context.Context, path string) (*ApplicationConfig, error) {
 	configStr := string(data)
 	configStr = replaceEnvVariables(configStr)
 
-	var config ApplicationConfig
+	config := ApplicationConfig{
+		Layout: DefaultLayout(),
+	}
+	config.Import.CsvProfiles = DefaultCsvProfiles()
+	// configurations written before the other transports existed have no whatsapp.is_enabled
+	config.WhatsApp.IsEnabled = true
 	err = yaml.Unmarshal([]byte(configStr), &config)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = config.Layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err = config.resolveBindings(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var slugRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Binding ties a chat to the spreadsheet its messages are recorded in.
+// Layout and AI default to the top-level settings when omitted.
+type Binding struct {
+	GroupName   string    `yaml:"group_name"`
+	IsArchived  bool      `yaml:"is_archived"`
+	SheetId     string    `yaml:"sheet_id"`
+	RawLayout   yaml.Node `yaml:"layout"`
+	IsAiEnabled *bool     `yaml:"is_ai_enabled"`
+	// TelegramChatIds are the allowed telegram chats recorded in this binding's spreadsheet
+	TelegramChatIds []int64 `yaml:"telegram_chat_ids"`
+	// Layout is RawLayout applied over the top-level layout, resolved by InitConfig
+	Layout Layout `yaml:"-"`
+}
+
+// ChatBindings returns the configured bindings, or a single one built from the top-level settings
+func (c *ApplicationConfig) ChatBindings() []Binding {
+	if len(c.Bindings) > 0 {
+		return c.Bindings
+	}
+
+	return []Binding{{
+		GroupName:  c.WhatsApp.GroupName,
+		IsArchived: c.WhatsApp.IsArchived,
+		SheetId:    c.Google.SheetId,
+		Layout:     c.Layout,
+	}}
+}
+
+// ForBinding returns a copy of the configuration pointing at the binding's chat and spreadsheet.
+// When several bindings are declared, each one gets its own budgets, recurring and sqlite files.
+func (c *ApplicationConfig) ForBinding(binding Binding) *ApplicationConfig {
+	config := *c
+	config.WhatsApp.GroupName = binding.GroupName
+	config.WhatsApp.IsArchived = binding.IsArchived
+	config.Google.SheetId = binding.SheetId
+	config.Layout = binding.Layout
+	if binding.IsAiEnabled != nil {
+		config.Ai.IsEnabled = *binding.IsAiEnabled
+	}
+
+	if len(c.Bindings) > 1 {
+		config.Budgets.FilePath = bindingFilePath(c.Budgets.FilePath, binding.GroupName)
+		config.Recurring.FilePath = bindingFilePath(c.Recurring.FilePath, binding.GroupName)
+		config.Storage.SQLitePath = bindingFilePath(c.Storage.SQLitePath, binding.GroupName)
+	}
+
+	return &config
+}
+
+func (c *ApplicationConfig) resolveBindings() error {
+	groupNames := make(map[string]bool)
+	telegramChats := make(map[int64]bool)
+	for i := range c.Bindings {
+		binding := &c.Bindings[i]
+		if binding.GroupName == "" || binding.SheetId == "" {
+			return errors.New("every binding needs a group_name and a sheet_id")
+		}
+		if groupNames[binding.GroupName] {
+			return fmt.Errorf("group %q is bound more than once", binding.GroupName)
+		}
+		groupNames[binding.GroupName] = true
+		for _, chatId := range binding.TelegramChatIds {
+			if telegramChats[chatId] {
+				return fmt.Errorf("telegram chat %d is bound more than once", chatId)
+			}
+			telegramChats[chatId] = true
+		}
+
+		binding.Layout = c.Layout
+		if !binding.RawLayout.IsZero() {
+			if err := binding.RawLayout.Decode(&binding.Layout); err != nil {
+				return fmt.Errorf("invalid layout for group %q: %w", binding.GroupName, err)
+			}
+		}
+		if err := binding.Layout.Validate(); err != nil {
+			return fmt.Errorf("invalid layout for group %q: %w", binding.GroupName, err)
+		}
+	}
+
+	// with a single spreadsheet every chat and the http api write to it, otherwise they must pick one
+	if c.Http.GroupName != "" && len(c.Bindings) > 0 && !groupNames[c.Http.GroupName] {
+		return fmt.Errorf("http group %q is not bound", c.Http.GroupName)
+	}
+	if len(c.Bindings) < 2 {
+		return nil
+	}
+	if c.Http.IsEnabled && c.Http.GroupName == "" {
+		return errors.New("http.group_name is required when several groups are bound")
+	}
+	if c.Telegram.IsEnabled {
+		for _, chatId := range c.Telegram.AllowedChatIds {
+			if !telegramChats[chatId] {
+				return fmt.Errorf("telegram chat %d is not bound to any group", chatId)
+			}
+		}
+	}
+
+	return nil
+}
+
+// bindingFilePath adds the group to a file name, e.g. "./budgets.json" becomes "./budgets-casa-silva.json"
+func bindingFilePath(path, groupName string) string {
+	if path == "" || path == ":memory:" {
+		return path
+	}
+
+	slug := strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(groupName), "-"), "-")
+	extension := filepath.Ext(path)
+	return strings.TrimSuffix(path, extension) + "-" + slug + extension
+}
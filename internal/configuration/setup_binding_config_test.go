@@ -0,0 +1,186 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// newTestConfig reads the yaml over the defaults, the way InitConfig does
+func newTestConfig(t *testing.T, data string) *ApplicationConfig {
+	t.Helper()
+
+	config := &ApplicationConfig{
+		Layout: DefaultLayout(),
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(data), config))
+	return config
+}
+
+func TestApplicationConfig_ResolveBindings(t *testing.T) {
+
+	_ = t.Run("rejects a group bound twice", func(t *testing.T) {
+		// arrange
+		config := newTestConfig(t, `
+bindings:
+  - {group_name: "casa", sheet_id: "1"}
+  - {group_name: "casa", sheet_id: "2"}
+`)
+
+		// act
+		err := config.resolveBindings()
+
+		// assert
+		_ = assert.EqualError(t, err, `group "casa" is bound more than once`)
+	})
+
+	_ = t.Run("applies a partial layout over the top-level one", func(t *testing.T) {
+		// arrange
+		config := newTestConfig(t, `
+layout:
+  columns_per_month: 7
+bindings:
+  - {group_name: "casa", sheet_id: "1"}
+  - group_name: "praia"
+    sheet_id: "2"
+    layout:
+      header_rows: 3
+`)
+
+		// act
+		err := config.resolveBindings()
+
+		// assert
+		require.NoError(t, err)
+		expected := DefaultLayout()
+		expected.ColumnsPerMonth = 7
+		_ = assert.Equal(t, expected, config.Bindings[0].Layout)
+		expected.HeaderRows = 3
+		_ = assert.Equal(t, expected, config.Bindings[1].Layout)
+	})
+
+	_ = t.Run("rejects an invalid layout override", func(t *testing.T) {
+		// arrange
+		config := newTestConfig(t, `
+bindings:
+  - group_name: "casa"
+    sheet_id: "1"
+    layout:
+      balance_offset: 6
+`)
+
+		// act
+		err := config.resolveBindings()
+
+		// assert
+		_ = assert.ErrorContains(t, err, `invalid layout for group "casa"`)
+	})
+
+	_ = t.Run("telegram chats and the http api must pick a group", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			data     string
+			expected string
+		}{
+			{
+				name: "unbound telegram chat",
+				data: `
+telegram: {is_enabled: true, allowed_chat_ids: [10, 20]}
+bindings:
+  - {group_name: "casa", sheet_id: "1", telegram_chat_ids: [10]}
+  - {group_name: "praia", sheet_id: "2"}
+`,
+				expected: "telegram chat 20 is not bound to any group",
+			},
+			{
+				name: "telegram chat bound twice",
+				data: `
+bindings:
+  - {group_name: "casa", sheet_id: "1", telegram_chat_ids: [10]}
+  - {group_name: "praia", sheet_id: "2", telegram_chat_ids: [10]}
+`,
+				expected: "telegram chat 10 is bound more than once",
+			},
+			{
+				name: "http api without group",
+				data: `
+http: {is_enabled: true}
+bindings:
+  - {group_name: "casa", sheet_id: "1"}
+  - {group_name: "praia", sheet_id: "2"}
+`,
+				expected: "http.group_name is required when several groups are bound",
+			},
+			{
+				name: "http api with an unknown group",
+				data: `
+http: {is_enabled: true, group_name: "sitio"}
+bindings:
+  - {group_name: "casa", sheet_id: "1"}
+`,
+				expected: `http group "sitio" is not bound`,
+			},
+		}
+
+		for _, test := range tests {
+			_ = t.Run(test.name, func(t *testing.T) {
+				// arrange
+				config := newTestConfig(t, test.data)
+
+				// act
+				err := config.resolveBindings()
+
+				// assert
+				_ = assert.EqualError(t, err, test.expected)
+			})
+		}
+	})
+}
+
+func TestApplicationConfig_ForBinding(t *testing.T) {
+
+	_ = t.Run("several bindings get their own files", func(t *testing.T) {
+		// arrange
+		config := newTestConfig(t, `
+budgets: {file_path: "./budgets.json"}
+recurring: {file_path: "./data/recurring.json"}
+storage: {sqlite_path: ":memory:"}
+bindings:
+  - {group_name: "Casa Silva!", sheet_id: "1"}
+  - {group_name: "praia", sheet_id: "2", is_ai_enabled: false}
+`)
+		config.Ai.IsEnabled = true
+		require.NoError(t, config.resolveBindings())
+
+		// act
+		casa := config.ForBinding(config.Bindings[0])
+		praia := config.ForBinding(config.Bindings[1])
+
+		// assert
+		_ = assert.Equal(t, "./budgets-casa-silva.json", casa.Budgets.FilePath)
+		_ = assert.Equal(t, "./data/recurring-casa-silva.json", casa.Recurring.FilePath)
+		_ = assert.Equal(t, ":memory:", casa.Storage.SQLitePath)
+		_ = assert.Equal(t, "1", casa.Google.SheetId)
+		_ = assert.True(t, casa.Ai.IsEnabled)
+		_ = assert.Equal(t, "./budgets-praia.json", praia.Budgets.FilePath)
+		_ = assert.False(t, praia.Ai.IsEnabled)
+	})
+
+	_ = t.Run("a single binding keeps the configured files", func(t *testing.T) {
+		// arrange
+		config := newTestConfig(t, `
+budgets: {file_path: "./budgets.json"}
+bindings:
+  - {group_name: "casa", sheet_id: "1"}
+`)
+		require.NoError(t, config.resolveBindings())
+
+		// act
+		casa := config.ForBinding(config.Bindings[0])
+
+		// assert
+		_ = assert.Equal(t, "./budgets.json", casa.Budgets.FilePath)
+	})
+}
@@ -0,0 +1,36 @@
+package configuration
+
+// CsvProfile maps the columns of a bank statement, found by their header, to transactions.
+type CsvProfile struct {
+	Delimiter         string `yaml:"delimiter"`
+	DateColumn        string `yaml:"date_column"`
+	DateLayout        string `yaml:"date_layout"`
+	DescriptionColumn string `yaml:"description_column"`
+	AmountColumn      string `yaml:"amount_column"`
+	// DecimalComma is set when amounts are written as "1.234,56"
+	DecimalComma bool `yaml:"decimal_comma"`
+	// OutcomePositive is set for credit card statements, which list purchases as positive amounts
+	OutcomePositive bool `yaml:"outcome_positive"`
+}
+
+// DefaultCsvProfiles covers the statements exported by Nubank's credit card and Itaú's checking account.
+func DefaultCsvProfiles() map[string]CsvProfile {
+	return map[string]CsvProfile{
+		"nubank": {
+			Delimiter:         ",",
+			DateColumn:        "date",
+			DateLayout:        "2006-01-02",
+			DescriptionColumn: "title",
+			AmountColumn:      "amount",
+			OutcomePositive:   true,
+		},
+		"itau": {
+			Delimiter:         ";",
+			DateColumn:        "data",
+			DateLayout:        "02/01/2006",
+			DescriptionColumn: "lancamento",
+			AmountColumn:      "valor",
+			DecimalComma:      true,
+		},
+	}
+}
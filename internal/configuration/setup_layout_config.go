@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const YearPlaceholder = "{year}"
+
+var columnLettersRegex = regexp.MustCompile(`^[A-Z]+$`)
+
+// Layout describes where each month, day and field lives in the yearly tabs.
+// Offsets are zero-based positions inside a month block.
+type Layout struct {
+	ColumnsPerMonth    int    `yaml:"columns_per_month"`
+	FirstMonthColumn   string `yaml:"first_month_column"`
+	HeaderRows         int    `yaml:"header_rows"`
+	IncomeOffset       int    `yaml:"income_offset"`
+	DailyOutcomeOffset int    `yaml:"daily_outcome_offset"`
+	BalanceOffset      int    `yaml:"balance_offset"`
+	TabNamePattern     string `yaml:"tab_name_pattern"`
+	// CategoryTabPattern names the tab holding monthly totals per category, empty disables it
+	CategoryTabPattern string `yaml:"category_tab_pattern"`
+}
+
+func DefaultLayout() Layout {
+	return Layout{
+		ColumnsPerMonth:    6,
+		FirstMonthColumn:   "A",
+		HeaderRows:         2,
+		IncomeOffset:       1,
+		DailyOutcomeOffset: 3,
+		BalanceOffset:      4,
+		TabNamePattern:     YearPlaceholder,
+	}
+}
+
+func (l *Layout) Validate() error {
+	if l.ColumnsPerMonth <= 0 {
+		return errors.New("layout columns_per_month must be greater than zero")
+	}
+	if !columnLettersRegex.MatchString(l.FirstMonthColumn) {
+		return fmt.Errorf("layout first_month_column %q must be a column letter such as \"A\"", l.FirstMonthColumn)
+	}
+	if l.HeaderRows < 0 {
+		return errors.New("layout header_rows must not be negative")
+	}
+	if l.TabNamePattern == "" {
+		return errors.New("layout tab_name_pattern must not be empty")
+	}
+
+	offsets := map[string]int{
+		"income_offset":        l.IncomeOffset,
+		"daily_outcome_offset": l.DailyOutcomeOffset,
+		"balance_offset":       l.BalanceOffset,
+	}
+	for name, offset := range offsets {
+		if offset < 0 || offset >= l.ColumnsPerMonth {
+			return fmt.Errorf("layout %s must be between 0 and %d", name, l.ColumnsPerMonth-1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayout_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(layout *Layout)
+		expected string
+	}{
+		{
+			name:   "default layout",
+			change: func(layout *Layout) {},
+		},
+		{
+			name:     "no columns per month",
+			change:   func(layout *Layout) { layout.ColumnsPerMonth = 0 },
+			expected: "layout columns_per_month must be greater than zero",
+		},
+		{
+			name:     "first month column is not a letter",
+			change:   func(layout *Layout) { layout.FirstMonthColumn = "a1" },
+			expected: `layout first_month_column "a1" must be a column letter such as "A"`,
+		},
+		{
+			name:     "negative header rows",
+			change:   func(layout *Layout) { layout.HeaderRows = -1 },
+			expected: "layout header_rows must not be negative",
+		},
+		{
+			name:     "empty tab name pattern",
+			change:   func(layout *Layout) { layout.TabNamePattern = "" },
+			expected: "layout tab_name_pattern must not be empty",
+		},
+		{
+			name:     "offset outside the month block",
+			change:   func(layout *Layout) { layout.BalanceOffset = 6 },
+			expected: "layout balance_offset must be between 0 and 5",
+		},
+		{
+			name:     "negative offset",
+			change:   func(layout *Layout) { layout.IncomeOffset = -1 },
+			expected: "layout income_offset must be between 0 and 5",
+		},
+	}
+
+	for _, test := range tests {
+		_ = t.Run(test.name, func(t *testing.T) {
+			// arrange
+			layout := DefaultLayout()
+			test.change(&layout)
+
+			// act
+			err := layout.Validate()
+
+			// assert
+			if test.expected == "" {
+				_ = assert.NoError(t, err)
+				return
+			}
+			_ = assert.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package configuration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations are applied in order, the index of the last applied one is kept in PRAGMA user_version
+var sqliteMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS transactions (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		day         TEXT    NOT NULL,
+		amount      INTEGER NOT NULL,
+		description TEXT    NOT NULL,
+		direction   TEXT    NOT NULL CHECK (direction IN ('income', 'outcome')),
+		author      TEXT    NOT NULL DEFAULT '',
+		created_at  TEXT    NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS transactions_day_idx ON transactions (day);
+	CREATE TABLE IF NOT EXISTS zeroed_days (
+		day TEXT PRIMARY KEY
+	);`,
+	`ALTER TABLE transactions ADD COLUMN category TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE transactions ADD COLUMN original_amount INTEGER NOT NULL DEFAULT 0;`,
+}
+
+func BuildSQLiteDB(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows a single writer, sharing one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSQLiteDB(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func migrateSQLiteDB(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
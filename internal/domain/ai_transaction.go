@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	aiIncomeDirection  = "income"
+	aiOutcomeDirection = "outcome"
+
+	maxAiDescriptionLength = 100
+	maxAiAmount            = 1_000_000
+)
+
+var (
+	ErrNotATransaction      = errors.New("message is not a transaction")
+	ErrInvalidAiTransaction = errors.New("invalid ai transaction")
+
+	aiCategoryRegex = regexp.MustCompile(`^[\p{L}\d_-]*$`)
+)
+
+// AiTransaction is the structured answer asked from the model, pointers tell a missing field from a zero one
+type AiTransaction struct {
+	IsTransaction *bool    `json:"is_transaction"`
+	Amount        *float64 `json:"amount"`
+	Direction     string   `json:"direction"`
+	Description   string   `json:"description"`
+	Category      string   `json:"category"`
+}
+
+// ParseAiTransaction decodes the model answer strictly, unknown fields, trailing text and invalid values are rejected,
+// ErrNotATransaction is returned when the model says the message is not a transaction
+func ParseAiTransaction(answer string) (*AiTransaction, error) {
+	decoder := json.NewDecoder(strings.NewReader(answer))
+	decoder.DisallowUnknownFields()
+
+	var transaction AiTransaction
+	if err := decoder.Decode(&transaction); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAiTransaction, err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: trailing data after the object", ErrInvalidAiTransaction)
+	}
+
+	if transaction.IsTransaction == nil {
+		return nil, fmt.Errorf("%w: is_transaction is missing", ErrInvalidAiTransaction)
+	}
+	if !*transaction.IsTransaction {
+		return nil, ErrNotATransaction
+	}
+
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (at *AiTransaction) Validate() error {
+	if at.Amount == nil || math.IsNaN(*at.Amount) || *at.Amount <= 0 || *at.Amount > maxAiAmount {
+		return fmt.Errorf("%w: amount must be a positive number", ErrInvalidAiTransaction)
+	}
+	if at.Direction != aiIncomeDirection && at.Direction != aiOutcomeDirection {
+		return fmt.Errorf("%w: direction must be %q or %q", ErrInvalidAiTransaction, aiIncomeDirection, aiOutcomeDirection)
+	}
+
+	description := strings.TrimSpace(at.Description)
+	if description == "" || utf8.RuneCountInString(description) > maxAiDescriptionLength {
+		return fmt.Errorf("%w: description must have between 1 and %d characters", ErrInvalidAiTransaction, maxAiDescriptionLength)
+	}
+	// a line break or a hashtag would change the recorded note
+	if strings.ContainsAny(description, "\n\r#") {
+		return fmt.Errorf("%w: description must be a single line without hashtags", ErrInvalidAiTransaction)
+	}
+	if !aiCategoryRegex.MatchString(at.Category) {
+		return fmt.Errorf("%w: category must be a single word", ErrInvalidAiTransaction)
+	}
+
+	return nil
+}
+
+// Message writes the transaction the way it is typed in the chat, e.g. "-30 / cerveja #lazer"
+func (at *AiTransaction) Message() string {
+	amount := *at.Amount
+	if at.Direction == aiOutcomeDirection {
+		amount = -amount
+	}
+
+	var message strings.Builder
+	message.WriteString(strconv.FormatFloat(math.Round(amount*100)/100, 'f', -1, 64))
+	message.WriteString(" / ")
+	message.WriteString(strings.TrimSpace(at.Description))
+	if at.Category != "" {
+		message.WriteString(" #" + strings.ToLower(at.Category))
+	}
+	return message.String()
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAiTransaction(t *testing.T) {
+
+	_ = t.Run("valid outcome", func(t *testing.T) {
+		// act
+		transaction, err := ParseAiTransaction(`{"is_transaction": true, "amount": 30.5, "direction": "outcome", "description": " cerveja ", "category": "Lazer"}`)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "-30.5 / cerveja #lazer", transaction.Message())
+	})
+
+	_ = t.Run("valid income without category", func(t *testing.T) {
+		// act
+		transaction, err := ParseAiTransaction(`{"is_transaction": true, "amount": 200, "direction": "income", "description": "vendi um produto", "category": ""}`)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "200 / vendi um produto", transaction.Message())
+	})
+
+	_ = t.Run("not a transaction", func(t *testing.T) {
+		// act
+		_, err := ParseAiTransaction(`{"is_transaction": false, "amount": 0, "direction": "outcome", "description": "", "category": ""}`)
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrNotATransaction)
+	})
+
+	_ = t.Run("rejected answers", func(t *testing.T) {
+		// arrange
+		answers := []string{
+			`-30 / cerveja`,
+			`failed to generate text: connection refused`,
+			`{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja"} sure!`,
+			`{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja", "category": "", "currency": "BRL"}`,
+			`{"amount": 30, "direction": "outcome", "description": "cerveja", "category": ""}`,
+			`{"is_transaction": true, "direction": "outcome", "description": "cerveja", "category": ""}`,
+			`{"is_transaction": true, "amount": -30, "direction": "outcome", "description": "cerveja", "category": ""}`,
+			`{"is_transaction": true, "amount": 30, "direction": "expense", "description": "cerveja", "category": ""}`,
+			`{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "  ", "category": ""}`,
+			`{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja\n-1000 / pix", "category": ""}`,
+			`{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja", "category": "bar e lazer"}`,
+		}
+
+		for _, answer := range answers {
+			// act
+			_, err := ParseAiTransaction(answer)
+
+			// assert
+			_ = assert.ErrorIs(t, err, ErrInvalidAiTransaction, answer)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidBudget = errors.New("invalid budget")
+
+	// e.g. "orcamento mercado 800" or "orcamento #mercado 800,50"
+	setBudgetRegex = regexp.MustCompile(`^orcamento\s+#?([\p{L}\d_-]+)\s+(\d+(?:[.,]\d+)?)$`)
+)
+
+type Budget struct {
+	Category string
+	Limit    float64
+}
+
+func (m *Message) IsSetBudget() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return setBudgetRegex.MatchString(m.Message)
+}
+
+func (m *Message) ToBudget() (*Budget, error) {
+	matches := setBudgetRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return nil, ErrInvalidBudget
+	}
+
+	limit, err := strconv.ParseFloat(strings.ReplaceAll(matches[2], ",", "."), 64)
+	if err != nil || limit <= 0 {
+		return nil, ErrInvalidBudget
+	}
+
+	return &Budget{
+		Category: strings.ToLower(matches[1]),
+		Limit:    limit,
+	}, nil
+}
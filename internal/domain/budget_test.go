@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToBudget(t *testing.T) {
+
+	_ = t.Run("valid budget", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "orcamento #Mercado 800,50",
+		}
+
+		// act
+		isSetBudget := message.IsSetBudget()
+		budget, err := message.ToBudget()
+
+		// assert
+		_ = assert.True(t, isSetBudget)
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "mercado", budget.Category)
+		_ = assert.Equal(t, 800.5, budget.Limit)
+	})
+
+	_ = t.Run("missing limit", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "orcamento mercado",
+		}
+
+		// act
+		_, err := message.ToBudget()
+
+		// assert
+		_ = assert.False(t, message.IsSetBudget())
+		_ = assert.ErrorIs(t, err, ErrInvalidBudget)
+	})
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BaseCurrency is the currency the sheet is kept in, transactions in other currencies are converted to it
+const BaseCurrency = "BRL"
+
+var (
+	ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+
+	// e.g. "cotacao usd 5,40"
+	setExchangeRateRegex = regexp.MustCompile(`^cotacao\s+([a-z]{3})\s+(\d+(?:[.,]\d+)?)$`)
+	// e.g. "-15 USD", "-15usd" or "-15 US$"
+	amountCurrencyRegex = regexp.MustCompile(`^(-?\d+(?:[.,]\d+)?)\s?([A-Za-z]{3}|[A-Z]{0,2}\$|€|£)?$`)
+
+	currencySymbols = map[string]string{
+		"R$":  BaseCurrency,
+		"US$": "USD",
+		"$":   "USD",
+		"€":   "EUR",
+		"£":   "GBP",
+	}
+)
+
+type ExchangeRate struct {
+	Currency string
+	// Rate is the value of one unit of the currency in BaseCurrency
+	Rate float64
+}
+
+func (m *Message) IsSetExchangeRate() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return setExchangeRateRegex.MatchString(m.Message)
+}
+
+func (m *Message) ToExchangeRate() (*ExchangeRate, error) {
+	matches := setExchangeRateRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	currency := strings.ToUpper(matches[1])
+	rate, err := strconv.ParseFloat(strings.ReplaceAll(matches[2], ",", "."), 64)
+	if err != nil || rate <= 0 || currency == BaseCurrency {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	return &ExchangeRate{
+		Currency: currency,
+		Rate:     rate,
+	}, nil
+}
+
+// Convert turns a transaction in a foreign currency into BaseCurrency, keeping the original amount
+func (t *Transaction) Convert(rate float64) {
+	t.OriginalAmount = t.Amount
+	t.Amount = math.Round(t.Amount*rate*100) / 100
+}
+
+// parseAmount splits the value of a transaction into amount and currency, the base currency is returned as ""
+func parseAmount(value string) (float64, string, error) {
+	matches := amountCurrencyRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return 0, "", ErrInvalidTransaction
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(matches[1], ",", "."), 64)
+	if err != nil {
+		return 0, "", ErrInvalidTransaction
+	}
+
+	currency := strings.ToUpper(matches[2])
+	if code, ok := currencySymbols[currency]; ok {
+		currency = code
+	}
+	if currency == BaseCurrency {
+		currency = ""
+	}
+
+	return amount, currency, nil
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToTransactionCurrency(t *testing.T) {
+
+	_ = t.Run("currency code", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-15.50 usd / taxi #transporte",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.True(t, message.IsIncomeOrOutcome())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, -15.5, transaction.Amount)
+		_ = assert.Equal(t, "USD", transaction.Currency)
+		_ = assert.Equal(t, "taxi", transaction.Description)
+	})
+
+	_ = t.Run("currency symbol", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-15 US$ / taxi",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "USD", transaction.Currency)
+	})
+
+	_ = t.Run("base currency", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-15 R$ / taxi",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Empty(t, transaction.Currency)
+	})
+
+	_ = t.Run("note round trip", func(t *testing.T) {
+		// arrange
+		transaction := &Transaction{Amount: -15, Description: "taxi", Category: "transporte", Currency: "USD"}
+		transaction.Convert(5.4)
+
+		// act
+		parsed, err := ParseNote(transaction.Note())
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "81.00 - taxi #transporte (USD 15.00)", transaction.Note())
+		_ = assert.Equal(t, 81.0, parsed.Amount)
+		_ = assert.Equal(t, "USD", parsed.Currency)
+		_ = assert.Equal(t, 15.0, parsed.OriginalAmount)
+		_ = assert.Equal(t, "taxi", parsed.Description)
+		_ = assert.Equal(t, "transporte", parsed.Category)
+	})
+}
+
+func TestMessage_ToExchangeRate(t *testing.T) {
+
+	_ = t.Run("valid rate", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "cotacao usd 5,40",
+		}
+
+		// act
+		exchangeRate, err := message.ToExchangeRate()
+
+		// assert
+		_ = assert.True(t, message.IsSetExchangeRate())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "USD", exchangeRate.Currency)
+		_ = assert.Equal(t, 5.4, exchangeRate.Rate)
+	})
+
+	_ = t.Run("base currency", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "cotacao brl 1",
+		}
+
+		// act
+		_, err := message.ToExchangeRate()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidExchangeRate)
+	})
+}
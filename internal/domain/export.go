@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidExport = errors.New("invalid export period")
+
+	// e.g. "exportar", "exportar 2025" or "exportar 01/01/2025 31/03/2025"
+	exportRegex = regexp.MustCompile(`^exportar(?:\s+(\d{4})|\s+(\d{1,2}/\d{1,2}/\d{4})\s+(\d{1,2}/\d{1,2}/\d{4}))?$`)
+)
+
+const exportDateLayout = "2/1/2006"
+
+func (m *Message) IsExport() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return exportRegex.MatchString(m.Message)
+}
+
+// ToExportPeriod returns the first and last days to export, both included, the current year up to today when omitted
+func (m *Message) ToExportPeriod(now time.Time) (time.Time, time.Time, error) {
+	matches := exportRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return time.Time{}, time.Time{}, ErrInvalidExport
+	}
+
+	switch {
+	case matches[1] != "":
+		year, _ := strconv.Atoi(matches[1])
+		return time.Date(year, 1, 1, 0, 0, 0, 0, now.Location()), time.Date(year, 12, 31, 0, 0, 0, 0, now.Location()), nil
+	case matches[2] != "":
+		from, errFrom := time.ParseInLocation(exportDateLayout, matches[2], now.Location())
+		to, errTo := time.ParseInLocation(exportDateLayout, matches[3], now.Location())
+		if errFrom != nil || errTo != nil || from.After(to) {
+			return time.Time{}, time.Time{}, ErrInvalidExport
+		}
+		return from, to, nil
+	default:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToExportPeriod(t *testing.T) {
+	now := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("current year up to today when omitted", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "exportar",
+		}
+
+		// act
+		from, to, err := message.ToExportPeriod(now)
+
+		// assert
+		_ = assert.True(t, message.IsExport())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), from)
+		_ = assert.Equal(t, time.Date(2026, 3, 12, 0, 0, 0, 0, time.Local), to)
+	})
+
+	_ = t.Run("whole year", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "exportar 2025",
+		}
+
+		// act
+		from, to, err := message.ToExportPeriod(now)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local), from)
+		_ = assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.Local), to)
+	})
+
+	_ = t.Run("explicit period", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "exportar 15/1/2025 28/02/2025",
+		}
+
+		// act
+		from, to, err := message.ToExportPeriod(now)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local), from)
+		_ = assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.Local), to)
+	})
+
+	_ = t.Run("invalid periods", func(t *testing.T) {
+		// arrange
+		reversed := Message{Message: "exportar 28/02/2025 15/01/2025"}
+		invalidDay := Message{Message: "exportar 01/02/2025 31/02/2025"}
+
+		// act
+		_, _, errReversed := reversed.ToExportPeriod(now)
+		_, _, errInvalidDay := invalidDay.ToExportPeriod(now)
+
+		// assert
+		_ = assert.ErrorIs(t, errReversed, ErrInvalidExport)
+		_ = assert.ErrorIs(t, errInvalidDay, ErrInvalidExport)
+	})
+}
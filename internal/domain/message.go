@@ -3,10 +3,17 @@ package domain
 import (
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Message struct {
-	Message string
+	ID        string
+	ChatID    string
+	Sender    string
+	Timestamp time.Time
+	Message   string
+	// Attachment is the path of a file sent along with the message, e.g. a chart
+	Attachment string
 }
 
 const (
@@ -20,8 +27,11 @@ const (
 	detailedDailyMessage = "notas"
 	dailyBalance         = "saldo"
 	setAsZero            = "zerar"
+	categoriesMessage    = "categorias"
+	budgetsMessage       = "orcamentos"
+	undoMessage          = "desfazer"
 	sysDailyReminder     = "sysdailyreminder"
-	regex                = `^-?\d+(?:[.,]\d+)?\s\/\s.+$`
+	regex                = `^-?\d+(?:[.,]\d+)?(?:\s?(?:[A-Za-z]{3}|[A-Z]{0,2}\$|€|£))?\s\/\s.+$`
 )
 
 func (m *Message) CheckIfIsSystemMessage() bool {
@@ -39,6 +49,11 @@ func (m *Message) Normalize() {
 		m.Message = strings.ToLower(m.Message)
 		// in pt-br the message 'diario' can be written with an accent
 		m.Message = strings.ReplaceAll(m.Message, "á", "a")
+		// and 'orcamento' or 'cotacao' with a cedilla and a tilde
+		m.Message = strings.ReplaceAll(m.Message, "ç", "c")
+		m.Message = strings.ReplaceAll(m.Message, "ã", "a")
+		// and 'relatorio' with an acute accent
+		m.Message = strings.ReplaceAll(m.Message, "ó", "o")
 	}
 }
 
@@ -109,3 +124,39 @@ func (m *Message) IsReminderVerification() bool {
 
 	return false
 }
+
+func (m *Message) IsCategories() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	if m.Message == categoriesMessage {
+		return true
+	}
+
+	return false
+}
+
+func (m *Message) IsBudgets() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	if m.Message == budgetsMessage {
+		return true
+	}
+
+	return false
+}
+
+func (m *Message) IsUndo() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	if m.Message == undoMessage {
+		return true
+	}
+
+	return false
+}
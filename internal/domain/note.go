@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrNoteNotFound = errors.New("note not found")
+
+	// e.g. "remover 2" or "editar 2 -25 / uber", N is the one-based position listed by "notas"
+	removeNoteRegex = regexp.MustCompile(`^remover\s+(\d+)$`)
+	editNoteRegex   = regexp.MustCompile(`^editar\s+(\d+)\s+(.+)$`)
+)
+
+func (m *Message) IsRemoveNote() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return removeNoteRegex.MatchString(m.Message)
+}
+
+func (m *Message) IsEditNote() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return editNoteRegex.MatchString(m.Message)
+}
+
+// ToNoteIndex returns the one-based position given to "remover N"
+func (m *Message) ToNoteIndex() (int, error) {
+	matches := removeNoteRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return 0, ErrInvalidTransaction
+	}
+
+	return strconv.Atoi(matches[1])
+}
+
+// ToNoteEdit returns the one-based position and the outcome given to "editar N value / description"
+func (m *Message) ToNoteEdit() (int, *Transaction, error) {
+	matches := editNoteRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return 0, nil, ErrInvalidTransaction
+	}
+
+	index, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, nil, ErrInvalidTransaction
+	}
+
+	transaction, err := (&Message{Sender: m.Sender, Message: matches[2]}).ToTransaction()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// the daily notes only hold outcomes, and only today's notes are edited so a date qualifier cannot move one
+	if transaction.IsIncome() || !transaction.Date.IsZero() {
+		return 0, nil, ErrInvalidTransaction
+	}
+
+	return index, transaction, nil
+}
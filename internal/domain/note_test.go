@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToNoteEdit(t *testing.T) {
+
+	_ = t.Run("valid edit", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "editar 2 -25,50 / uber #transporte",
+		}
+
+		// act
+		index, transaction, err := message.ToNoteEdit()
+
+		// assert
+		_ = assert.True(t, message.IsEditNote())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 2, index)
+		_ = assert.Equal(t, -25.5, transaction.Amount)
+		_ = assert.Equal(t, "uber", transaction.Description)
+		_ = assert.Equal(t, "transporte", transaction.Category)
+	})
+
+	_ = t.Run("income is rejected", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "editar 1 25 / uber",
+		}
+
+		// act
+		_, _, err := message.ToNoteEdit()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+
+	_ = t.Run("date qualifier is rejected", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "editar 1 -25 / uber ontem",
+		}
+
+		// act
+		_, _, err := message.ToNoteEdit()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+
+	_ = t.Run("remove does not match remove recurring", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "remover recorrente 1",
+		}
+
+		// act
+		isRemoveNote := message.IsRemoveNote()
+
+		// assert
+		_ = assert.False(t, isRemoveNote)
+		_ = assert.True(t, message.IsRemoveRecurring())
+	})
+}
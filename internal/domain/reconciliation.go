@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// descriptions at least this similar are taken as the same purchase
+	similarityThreshold = 0.4
+	// ReconcileDayTolerance is how many days apart a statement line and a logged transaction may be,
+	// card purchases are usually posted a day after they are logged
+	ReconcileDayTolerance = 1
+)
+
+var accentReplacer = strings.NewReplacer("á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c")
+
+// Reconciliation compares the lines of a bank statement with the transactions logged through the bot
+type Reconciliation struct {
+	Matched []ReconciledPair
+	// Unlogged are in the statement but were never logged
+	Unlogged []*Transaction
+	// Unmatched were logged but are not in the statement
+	Unmatched []*Transaction
+}
+
+type ReconciledPair struct {
+	Statement *Transaction
+	Logged    *Transaction
+}
+
+// Reconcile pairs each statement line with a logged transaction of the same value, on the same day or the next,
+// whose description is similar enough; logged values without notes only match by day and value
+func Reconcile(statement, logged []*Transaction) *Reconciliation {
+	reconciliation := &Reconciliation{}
+	paired := make([]bool, len(logged))
+
+	for _, line := range statement {
+		best, bestScore := -1, 0.0
+		for i, transaction := range logged {
+			if paired[i] || math.Round(transaction.Amount*100) != math.Round(line.Amount*100) {
+				continue
+			}
+			dayDiff := math.Abs(line.Date.Sub(transaction.Date).Hours() / 24)
+			if math.Round(dayDiff) > ReconcileDayTolerance {
+				continue
+			}
+
+			similarity := 1.0
+			if transaction.Description != "" {
+				similarity = DescriptionSimilarity(line.Description, transaction.Description)
+			}
+			if similarity < similarityThreshold {
+				continue
+			}
+
+			// the same day wins over a better description a day apart
+			if score := similarity - math.Round(dayDiff); best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		if best == -1 {
+			reconciliation.Unlogged = append(reconciliation.Unlogged, line)
+			continue
+		}
+		paired[best] = true
+		reconciliation.Matched = append(reconciliation.Matched, ReconciledPair{Statement: line, Logged: logged[best]})
+	}
+
+	for i, transaction := range logged {
+		if !paired[i] {
+			reconciliation.Unmatched = append(reconciliation.Unmatched, transaction)
+		}
+	}
+
+	return reconciliation
+}
+
+// DescriptionSimilarity compares two descriptions ignoring case, accents and punctuation, from 0 to 1,
+// a description contained in the other, like "uber" in "UBER *TRIP", is fully similar
+func DescriptionSimilarity(a, b string) float64 {
+	a, b = normalizeDescription(a), normalizeDescription(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return 1
+	}
+
+	// dice coefficient of the character pairs
+	pairs := make(map[string]int)
+	aPairs, bPairs := bigrams(a), bigrams(b)
+	if len(aPairs)+len(bPairs) == 0 {
+		return 0
+	}
+	for _, pair := range aPairs {
+		pairs[pair]++
+	}
+	common := 0
+	for _, pair := range bPairs {
+		if pairs[pair] > 0 {
+			pairs[pair]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(aPairs)+len(bPairs))
+}
+
+func normalizeDescription(description string) string {
+	description = accentReplacer.Replace(strings.ToLower(description))
+	return strings.Join(strings.FieldsFunc(description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func bigrams(value string) []string {
+	runes := []rune(value)
+	var pairs []string
+	for i := 0; i+1 < len(runes); i++ {
+		pairs = append(pairs, string(runes[i:i+2]))
+	}
+	return pairs
+}
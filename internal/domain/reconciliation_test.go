@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	day := time.Date(2026, 3, 12, 0, 0, 0, 0, time.Local)
+
+	_ = t.Run("matches by day, value and description", func(t *testing.T) {
+		// arrange
+		statement := []*Transaction{
+			{Amount: -25, Description: "UBER *TRIP", Date: day.AddDate(0, 0, 1)},
+			{Amount: -45.9, Description: "SUPERMERCADO EXTRA", Date: day},
+			{Amount: -45.9, Description: "FARMACIA SAO JOAO", Date: day},
+			{Amount: -80, Description: "POSTO SHELL", Date: day},
+		}
+		logged := []*Transaction{
+			{Amount: -45.9, Description: "farmácia", Date: day},
+			{Amount: -45.9, Description: "supermercado", Date: day},
+			{Amount: -25, Description: "uber", Date: day},
+			{Amount: -12, Description: "pao", Date: day},
+			{Amount: -80, Description: "gasolina", Date: day},
+		}
+
+		// act
+		reconciliation := Reconcile(statement, logged)
+
+		// assert
+		_ = assert.Equal(t, []ReconciledPair{
+			{Statement: statement[0], Logged: logged[2]},
+			{Statement: statement[1], Logged: logged[1]},
+			{Statement: statement[2], Logged: logged[0]},
+		}, reconciliation.Matched)
+		_ = assert.Equal(t, []*Transaction{statement[3]}, reconciliation.Unlogged)
+		_ = assert.Equal(t, []*Transaction{logged[3], logged[4]}, reconciliation.Unmatched)
+	})
+
+	_ = t.Run("values without notes match by day and value", func(t *testing.T) {
+		// arrange
+		statement := []*Transaction{{Amount: -30, Description: "RESTAURANTE", Date: day}}
+		logged := []*Transaction{{Amount: -30, Date: day.AddDate(0, 0, -2)}, {Amount: -30, Date: day}}
+
+		// act
+		reconciliation := Reconcile(statement, logged)
+
+		// assert
+		_ = assert.Equal(t, []ReconciledPair{{Statement: statement[0], Logged: logged[1]}}, reconciliation.Matched)
+		_ = assert.Equal(t, []*Transaction{logged[0]}, reconciliation.Unmatched)
+	})
+}
+
+func TestDescriptionSimilarity(t *testing.T) {
+
+	_ = t.Run("ignores case, accents and punctuation", func(t *testing.T) {
+		// act
+		contained := DescriptionSimilarity("PAG*Padaria Pão Quente", "padaria pao")
+		similar := DescriptionSimilarity("farmacia sao joao", "farmacias pague menos")
+		different := DescriptionSimilarity("posto shell", "gasolina")
+
+		// assert
+		_ = assert.Equal(t, 1.0, contained)
+		_ = assert.Greater(t, similar, 0.2)
+		_ = assert.Less(t, different, similarityThreshold)
+	})
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recurringListMessage = "recorrentes"
+	dateLayout           = "2006-01-02"
+	// a bot down for longer than this does not post what it missed before
+	maxCatchUpDays = 92
+)
+
+var (
+	ErrInvalidRecurring = errors.New("invalid recurring transaction")
+
+	// e.g. "recorrente -1500 / aluguel dia 5" or "recorrente -55,90 / netflix toda segunda"
+	addRecurringRegex    = regexp.MustCompile(`^recorrente\s+(.+?)\s+(?:dia\s+(\d{1,2})|tod[ao]s?\s+(?:as\s+|os\s+)?(\p{L}+)(?:-feira)?)$`)
+	removeRecurringRegex = regexp.MustCompile(`^remover\s+recorrente\s+(\d+)$`)
+
+	weekdays = map[string]time.Weekday{
+		"domingo": time.Sunday,
+		"segunda": time.Monday,
+		"terca":   time.Tuesday,
+		"quarta":  time.Wednesday,
+		"quinta":  time.Thursday,
+		"sexta":   time.Friday,
+		"sabado":  time.Saturday,
+	}
+)
+
+// Recurring is a transaction posted automatically on a day of the month or on a weekday
+type Recurring struct {
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Category    string  `json:"category,omitempty"`
+	// Currency is converted with the rate of the day the transaction is posted
+	Currency   string        `json:"currency,omitempty"`
+	DayOfMonth int           `json:"day_of_month,omitempty"`
+	Weekday    *time.Weekday `json:"weekday,omitempty"`
+	// LastRun is the last day the transaction was posted, so it is never posted twice on the same day
+	LastRun string `json:"last_run,omitempty"`
+}
+
+func (m *Message) IsAddRecurring() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return addRecurringRegex.MatchString(m.Message)
+}
+
+func (m *Message) IsRecurringList() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return m.Message == recurringListMessage
+}
+
+func (m *Message) IsRemoveRecurring() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return removeRecurringRegex.MatchString(m.Message)
+}
+
+func (m *Message) ToRecurring() (*Recurring, error) {
+	matches := addRecurringRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return nil, ErrInvalidRecurring
+	}
+
+	transaction, err := (&Message{Message: matches[1]}).ToTransaction()
+	if err != nil {
+		return nil, ErrInvalidRecurring
+	}
+
+	recurring := &Recurring{
+		Amount:      transaction.Amount,
+		Description: transaction.Description,
+		Category:    transaction.Category,
+		Currency:    transaction.Currency,
+	}
+
+	if matches[2] != "" {
+		day, err := strconv.Atoi(matches[2])
+		if err != nil || day < 1 || day > 31 {
+			return nil, ErrInvalidRecurring
+		}
+		recurring.DayOfMonth = day
+		return recurring, nil
+	}
+
+	weekday, ok := weekdays[matches[3]]
+	if !ok {
+		return nil, ErrInvalidRecurring
+	}
+	recurring.Weekday = &weekday
+	return recurring, nil
+}
+
+// ToRecurringIndex returns the one-based position given to "remover recorrente N"
+func (m *Message) ToRecurringIndex() (int, error) {
+	matches := removeRecurringRegex.FindStringSubmatch(m.Message)
+	if matches == nil {
+		return 0, ErrInvalidRecurring
+	}
+
+	return strconv.Atoi(matches[1])
+}
+
+func (r *Recurring) IsDue(now time.Time) bool {
+	return len(r.DueDays(now)) > 0
+}
+
+// DueDays returns the days the transaction should have been posted on since LastRun, up to now and oldest first,
+// so the runs missed while the bot was down are caught up; a transaction never run is only due on its own day
+func (r *Recurring) DueDays(now time.Time) []time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := today
+	if lastRun, err := time.ParseInLocation(dateLayout, r.LastRun, now.Location()); err == nil {
+		from = lastRun.AddDate(0, 0, 1)
+	}
+	if oldest := today.AddDate(0, 0, -maxCatchUpDays); from.Before(oldest) {
+		from = oldest
+	}
+
+	var days []time.Time
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if r.runsOn(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *Recurring) runsOn(day time.Time) bool {
+	if r.Weekday != nil {
+		return day.Weekday() == *r.Weekday
+	}
+
+	// a rule like "dia 31" runs on the last day of shorter months
+	lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	return day.Day() == min(r.DayOfMonth, lastDay)
+}
+
+func (r *Recurring) MarkRun(now time.Time) {
+	r.LastRun = now.Format(dateLayout)
+}
+
+func (r *Recurring) ToTransaction() *Transaction {
+	return &Transaction{
+		Amount:      r.Amount,
+		Description: r.Description,
+		Category:    r.Category,
+		Currency:    r.Currency,
+	}
+}
+
+func (r *Recurring) String() string {
+	description := r.Description
+	if r.Category != "" {
+		description += " #" + r.Category
+	}
+
+	rule := fmt.Sprintf("dia %d", r.DayOfMonth)
+	if r.Weekday != nil {
+		for name, weekday := range weekdays {
+			if weekday != *r.Weekday {
+				continue
+			}
+			rule = "toda " + name
+			// domingo and sabado are masculine in pt-br
+			if weekday == time.Sunday || weekday == time.Saturday {
+				rule = "todo " + name
+			}
+		}
+	}
+
+	amount := fmt.Sprint(r.Amount)
+	if r.Currency != "" {
+		amount += " " + r.Currency
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s / %s (%s)", amount, description, rule))
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToRecurring(t *testing.T) {
+
+	_ = t.Run("day of month", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "recorrente -1500 / aluguel #casa dia 5",
+		}
+
+		// act
+		recurring, err := message.ToRecurring()
+
+		// assert
+		_ = assert.True(t, message.IsAddRecurring())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, -1500.0, recurring.Amount)
+		_ = assert.Equal(t, "aluguel", recurring.Description)
+		_ = assert.Equal(t, "casa", recurring.Category)
+		_ = assert.Equal(t, 5, recurring.DayOfMonth)
+		_ = assert.Nil(t, recurring.Weekday)
+	})
+
+	_ = t.Run("weekday", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "recorrente -20 / feira todo sabado",
+		}
+
+		// act
+		recurring, err := message.ToRecurring()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Saturday, *recurring.Weekday)
+	})
+
+	_ = t.Run("invalid day", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "recorrente -20 / feira dia 32",
+		}
+
+		// act
+		_, err := message.ToRecurring()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidRecurring)
+	})
+}
+
+func TestRecurring_IsDue(t *testing.T) {
+
+	_ = t.Run("clamps the day to the end of the month", func(t *testing.T) {
+		// arrange
+		recurring := Recurring{Amount: -1500, Description: "aluguel", DayOfMonth: 31}
+
+		// act
+		dueOnLastDay := recurring.IsDue(time.Date(2026, 2, 28, 9, 0, 0, 0, time.Local))
+		dueBefore := recurring.IsDue(time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.True(t, dueOnLastDay)
+		_ = assert.False(t, dueBefore)
+	})
+
+	_ = t.Run("runs once a day", func(t *testing.T) {
+		// arrange
+		now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+		monday := time.Monday
+		recurring := Recurring{Amount: -55.9, Description: "netflix", Weekday: &monday}
+
+		// act
+		dueBefore := recurring.IsDue(now)
+		recurring.MarkRun(now)
+		dueAfter := recurring.IsDue(now.Add(time.Hour))
+
+		// assert
+		_ = assert.True(t, dueBefore)
+		_ = assert.False(t, dueAfter)
+	})
+
+	_ = t.Run("catches up the runs missed since the last one", func(t *testing.T) {
+		// arrange
+		recurring := Recurring{Amount: -1500, Description: "aluguel", DayOfMonth: 5, LastRun: "2026-02-05"}
+
+		// act
+		days := recurring.DueDays(time.Date(2026, 4, 10, 9, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Equal(t, []time.Time{
+			time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local),
+			time.Date(2026, 4, 5, 0, 0, 0, 0, time.Local),
+		}, days)
+	})
+
+	_ = t.Run("never run is only due on its own day", func(t *testing.T) {
+		// arrange
+		recurring := Recurring{Amount: -1500, Description: "aluguel", DayOfMonth: 5}
+
+		// act
+		due := recurring.IsDue(time.Date(2026, 4, 10, 9, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.False(t, due)
+	})
+}
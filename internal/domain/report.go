@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidReport = errors.New("invalid report")
+	ErrFutureMonth   = errors.New("month has not started yet")
+
+	// e.g. "relatorio" or "relatorio 03/2026"
+	reportRegex = regexp.MustCompile(`^relatorio(?:\s+(\d{1,2})/(\d{4}))?$`)
+	// e.g. "grafico" or "grafico 03/2026"
+	chartRegex = regexp.MustCompile(`^grafico(?:\s+(\d{1,2})/(\d{4}))?$`)
+)
+
+// MonthSummary holds the totals of a month, up to today for the current one
+type MonthSummary struct {
+	Month          time.Time
+	Income         float64
+	Outcome        float64
+	ClosingBalance float64
+	// DailyOutcomes has the diario value of each elapsed day, index 0 is the first day of the month
+	DailyOutcomes []float64
+}
+
+func (m *Message) IsReport() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return reportRegex.MatchString(m.Message)
+}
+
+func (m *Message) IsChart() bool {
+	if m.Message == "" {
+		return false
+	}
+
+	return chartRegex.MatchString(m.Message)
+}
+
+// ToReportMonth returns the first day of the month asked for, the current one when omitted
+func (m *Message) ToReportMonth(now time.Time) (time.Time, error) {
+	return toMonth(reportRegex.FindStringSubmatch(m.Message), now)
+}
+
+// ToChartMonth returns the first day of the month to chart, the current one when omitted
+func (m *Message) ToChartMonth(now time.Time) (time.Time, error) {
+	return toMonth(chartRegex.FindStringSubmatch(m.Message), now)
+}
+
+func toMonth(matches []string, now time.Time) (time.Time, error) {
+	if matches == nil {
+		return time.Time{}, ErrInvalidReport
+	}
+
+	if matches[1] == "" {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	month, _ := strconv.Atoi(matches[1])
+	year, _ := strconv.Atoi(matches[2])
+	if month < 1 || month > 12 {
+		return time.Time{}, ErrInvalidReport
+	}
+
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, now.Location())
+	// the days ahead only hold the default diario values
+	if first.After(now) {
+		return time.Time{}, ErrFutureMonth
+	}
+	return first, nil
+}
+
+// ElapsedDays returns how many days of the month are over, or counting today for the current month
+func ElapsedDays(month, now time.Time) int {
+	if IsCurrentMonth(month, now) {
+		return min(now.Day(), DaysInMonth(month))
+	}
+	if month.After(now) {
+		return 0
+	}
+	return DaysInMonth(month)
+}
+
+func DaysInMonth(month time.Time) int {
+	return time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
+}
+
+func IsCurrentMonth(month, now time.Time) bool {
+	return month.Year() == now.Year() && month.Month() == now.Month()
+}
+
+// BusiestDay returns the day of the month with the highest outcome, zero when nothing was spent
+func (ms *MonthSummary) BusiestDay() (int, float64) {
+	busiestDay, highest := 0, 0.0
+	for i, outcome := range ms.DailyOutcomes {
+		if outcome > highest {
+			busiestDay, highest = i+1, outcome
+		}
+	}
+	return busiestDay, highest
+}
+
+// OutcomeUntil sums the outcome of the first days of the month, to compare it with a month still going on
+func (ms *MonthSummary) OutcomeUntil(days int) float64 {
+	outcome := 0.0
+	for i := 0; i < days && i < len(ms.DailyOutcomes); i++ {
+		outcome += ms.DailyOutcomes[i]
+	}
+	return outcome
+}
+
+func (ms *MonthSummary) AverageDailySpend() float64 {
+	if len(ms.DailyOutcomes) == 0 {
+		return 0
+	}
+	return ms.Outcome / float64(len(ms.DailyOutcomes))
+}
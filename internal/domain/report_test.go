@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToReportMonth(t *testing.T) {
+	now := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("current month when omitted", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "relatorio",
+		}
+
+		// act
+		month, err := message.ToReportMonth(now)
+
+		// assert
+		_ = assert.True(t, message.IsReport())
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), month)
+		_ = assert.Equal(t, 12, ElapsedDays(month, now))
+	})
+
+	_ = t.Run("explicit month", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "relatorio 2/2026",
+		}
+
+		// act
+		month, err := message.ToReportMonth(now)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local), month)
+		_ = assert.Equal(t, 28, ElapsedDays(month, now))
+	})
+
+	_ = t.Run("future month", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "relatorio 4/2026",
+		}
+
+		// act
+		_, err := message.ToReportMonth(now)
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrFutureMonth)
+		_ = assert.Equal(t, 0, ElapsedDays(time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local), now))
+	})
+
+	_ = t.Run("invalid month", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "relatorio 13/2026",
+		}
+
+		// act
+		_, err := message.ToReportMonth(now)
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidReport)
+	})
+}
+
+func TestMonthSummary_BusiestDay(t *testing.T) {
+
+	_ = t.Run("highest outcome and average", func(t *testing.T) {
+		// arrange
+		summary := MonthSummary{Outcome: 90, DailyOutcomes: []float64{10, 50, 0, 30}}
+
+		// act
+		day, outcome := summary.BusiestDay()
+
+		// assert
+		_ = assert.Equal(t, 2, day)
+		_ = assert.Equal(t, 50.0, outcome)
+		_ = assert.Equal(t, 22.5, summary.AverageDailySpend())
+		_ = assert.Equal(t, 60.0, summary.OutcomeUntil(2))
+	})
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTransaction = errors.New("invalid transaction")
+	ErrInvalidNote        = errors.New("invalid note")
+	ErrDailyHasNotes      = errors.New("daily value has notes")
+	ErrNothingToUndo      = errors.New("nothing to undo")
+	ErrTransactionEdited  = errors.New("transaction was edited since it was recorded")
+
+	// e.g. the "(USD 15.00)" suffix of "75.00 - taxi (USD 15.00)"
+	originalAmountRegex = regexp.MustCompile(`\s*\(([A-Z]{3}) (\d+(?:\.\d+)?)\)$`)
+	// e.g. the "(@Ana)" suffix of "45.00 - mercado (@Ana)", the marker keeps a parenthetical typed by hand,
+	// like "mercado (promo)", in the description
+	authorRegex  = regexp.MustCompile(`\s*\(@([^()]+)\)$`)
+	hashtagRegex = regexp.MustCompile(`(?:^|\s)#([\p{L}\d_-]+)`)
+	// e.g. "-20 / padaria ontem" or "-20 / padaria 12/03"
+	dateQualifierRegex = regexp.MustCompile(`(?i)\s+(hoje|ontem|anteontem|(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?)$`)
+)
+
+type Transaction struct {
+	// Amount is positive for income and negative for outcome
+	Amount      float64
+	Description string
+	Category    string
+	Author      string
+	// Date is the day the transaction belongs to, zero means today
+	Date time.Time
+	// Currency is set when the transaction was given in a foreign currency, OriginalAmount keeps the amount in it
+	Currency       string
+	OriginalAmount float64
+}
+
+func (m *Message) ToTransaction() (*Transaction, error) {
+	value, description, found := strings.Cut(m.Message, "/")
+	if !found {
+		return nil, ErrInvalidTransaction
+	}
+
+	amount, currency, err := parseAmount(value)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	description, category := extractCategory(description)
+	description, date, err := extractDate(description, now)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &Transaction{
+		Amount:      amount,
+		Description: description,
+		Category:    category,
+		Author:      m.Sender,
+		Date:        date,
+		Currency:    currency,
+	}
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// ParseNote parses a note line written by Transaction.Note, the amount is always positive
+// since the direction is given by the cell the note belongs to.
+func ParseNote(note string) (*Transaction, error) {
+	value, description, found := strings.Cut(note, " - ")
+	if !found {
+		return nil, ErrInvalidNote
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, ErrInvalidNote
+	}
+
+	transaction := &Transaction{Amount: amount}
+	// the author comes last
+	if matches := authorRegex.FindStringSubmatch(description); matches != nil {
+		transaction.Author = matches[1]
+		description = description[:len(description)-len(matches[0])]
+	}
+	if matches := originalAmountRegex.FindStringSubmatch(description); matches != nil {
+		transaction.Currency = matches[1]
+		transaction.OriginalAmount, _ = strconv.ParseFloat(matches[2], 64)
+		description = description[:len(description)-len(matches[0])]
+	}
+
+	transaction.Description, transaction.Category = extractCategory(description)
+	return transaction, nil
+}
+
+func (t *Transaction) Validate() error {
+	if t.Amount == 0 || t.Description == "" {
+		return ErrInvalidTransaction
+	}
+	return nil
+}
+
+// Day returns the day the transaction belongs to, now when it has no date
+func (t *Transaction) Day(now time.Time) time.Time {
+	if t.Date.IsZero() {
+		return now
+	}
+	return t.Date
+}
+
+func (t *Transaction) IsIncome() bool {
+	return t.Amount > 0
+}
+
+// Note returns the line recorded in the sheet notes, e.g. "45.00 - mercado #alimentacao (@Ana)" or "75.00 - taxi (USD 15.00)"
+func (t *Transaction) Note() string {
+	note := fmt.Sprintf("%.2f - %s", math.Abs(t.Amount), t.Description)
+	if t.Category != "" {
+		note += " #" + t.Category
+	}
+	if t.Currency != "" {
+		note += fmt.Sprintf(" (%s %.2f)", t.Currency, math.Abs(t.OriginalAmount))
+	}
+	if t.Author != "" {
+		note += " (@" + t.Author + ")"
+	}
+	return note
+}
+
+func (t *Transaction) String() string {
+	return fmt.Sprintf("%v / %s", t.Amount, t.Description)
+}
+
+// extractCategory removes the first hashtag from the description and returns it as the category
+func extractCategory(description string) (string, string) {
+	match := hashtagRegex.FindStringSubmatchIndex(description)
+	if match == nil {
+		return strings.TrimSpace(description), ""
+	}
+
+	category := strings.ToLower(description[match[2]:match[3]])
+	description = description[:match[0]] + description[match[1]:]
+	return strings.Join(strings.Fields(description), " "), category
+}
+
+// extractDate removes a trailing date qualifier from the description and returns the day it refers to,
+// a day and month without a year is the last one that is not in the future
+func extractDate(description string, now time.Time) (string, time.Time, error) {
+	matches := dateQualifierRegex.FindStringSubmatch(description)
+	if matches == nil {
+		return description, time.Time{}, nil
+	}
+
+	description = strings.TrimSpace(description[:len(description)-len(matches[0])])
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(matches[1]) {
+	case "hoje":
+		return description, today, nil
+	case "ontem":
+		return description, today.AddDate(0, 0, -1), nil
+	case "anteontem":
+		return description, today.AddDate(0, 0, -2), nil
+	}
+
+	day, _ := strconv.Atoi(matches[2])
+	month, _ := strconv.Atoi(matches[3])
+	year := today.Year()
+	if matches[4] != "" {
+		year, _ = strconv.Atoi(matches[4])
+		if year < 100 {
+			year += 2000
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	// time.Date normalizes overflowing values, e.g. 31/02 becomes 03/03
+	if date.Day() != day || int(date.Month()) != month {
+		return "", time.Time{}, ErrInvalidTransaction
+	}
+
+	if date.After(today) {
+		if matches[4] != "" {
+			return "", time.Time{}, ErrInvalidTransaction
+		}
+		date = date.AddDate(-1, 0, 0)
+	}
+
+	return description, date, nil
+}
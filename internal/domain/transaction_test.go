@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_ToTransaction(t *testing.T) {
+
+	_ = t.Run("invalid message", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "invalid message",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+		_ = assert.Nil(t, transaction)
+	})
+
+	_ = t.Run("zero amount", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "0 / nothing",
+		}
+
+		// act
+		_, err := message.ToTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+
+	_ = t.Run("outcome", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-30,50 / cerveja / gelada",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, -30.5, transaction.Amount)
+		_ = assert.Equal(t, "cerveja / gelada", transaction.Description)
+		_ = assert.False(t, transaction.IsIncome())
+	})
+
+	_ = t.Run("income", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "200 / vendi um produto",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.True(t, transaction.IsIncome())
+		_ = assert.Equal(t, "200 / vendi um produto", transaction.String())
+	})
+}
+
+func TestMessage_ToTransactionCategory(t *testing.T) {
+
+	_ = t.Run("hashtag becomes the category", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-45 / mercado #Alimentacao do mes",
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "mercado do mes", transaction.Description)
+		_ = assert.Equal(t, "alimentacao", transaction.Category)
+		_ = assert.Equal(t, "45.00 - mercado do mes #alimentacao", transaction.Note())
+	})
+
+	_ = t.Run("only hashtag", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message: "-45 / #alimentacao",
+		}
+
+		// act
+		_, err := message.ToTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+}
+
+func TestParseNote(t *testing.T) {
+
+	_ = t.Run("note with category", func(t *testing.T) {
+		// act
+		transaction, err := ParseNote("45.00 - mercado #alimentacao")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 45.0, transaction.Amount)
+		_ = assert.Equal(t, "mercado", transaction.Description)
+		_ = assert.Equal(t, "alimentacao", transaction.Category)
+	})
+
+	_ = t.Run("legacy note", func(t *testing.T) {
+		// act
+		transaction, err := ParseNote("30.00 -  cerveja")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "cerveja", transaction.Description)
+		_ = assert.Empty(t, transaction.Category)
+	})
+
+	_ = t.Run("invalid note", func(t *testing.T) {
+		// act
+		_, err := ParseNote("written by hand")
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidNote)
+	})
+}
+
+func TestMessage_ToTransactionDate(t *testing.T) {
+	now := time.Date(2026, 1, 10, 15, 30, 0, 0, time.Local)
+
+	_ = t.Run("yesterday", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message:   "-20 / padaria #lanche Ontem",
+			Timestamp: now,
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "padaria", transaction.Description)
+		_ = assert.Equal(t, "lanche", transaction.Category)
+		_ = assert.Equal(t, time.Date(2026, 1, 9, 0, 0, 0, 0, time.Local), transaction.Date)
+	})
+
+	_ = t.Run("day before yesterday", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message:   "-20 / padaria anteontem",
+			Timestamp: now,
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, time.Date(2026, 1, 8, 0, 0, 0, 0, time.Local), transaction.Date)
+	})
+
+	_ = t.Run("day and month of the previous year", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message:   "-20 / padaria 28/12",
+			Timestamp: now,
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "padaria", transaction.Description)
+		_ = assert.Equal(t, time.Date(2025, 12, 28, 0, 0, 0, 0, time.Local), transaction.Date)
+	})
+
+	_ = t.Run("invalid day", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message:   "-20 / padaria 31/02",
+			Timestamp: now,
+		}
+
+		// act
+		_, err := message.ToTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidTransaction)
+	})
+
+	_ = t.Run("no qualifier", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Message:   "-20 / padaria",
+			Timestamp: now,
+		}
+
+		// act
+		transaction, err := message.ToTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.True(t, transaction.Date.IsZero())
+		_ = assert.Equal(t, now, transaction.Day(now))
+	})
+}
+
+func TestParseNoteAuthor(t *testing.T) {
+
+	_ = t.Run("author", func(t *testing.T) {
+		// arrange
+		message := Message{
+			Sender:  "Ana",
+			Message: "-45 / mercado #alimentacao",
+		}
+		transaction, _ := message.ToTransaction()
+
+		// act
+		parsed, err := ParseNote(transaction.Note())
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "45.00 - mercado #alimentacao (@Ana)", transaction.Note())
+		_ = assert.Equal(t, "Ana", parsed.Author)
+		_ = assert.Equal(t, "mercado", parsed.Description)
+		_ = assert.Equal(t, "alimentacao", parsed.Category)
+	})
+
+	_ = t.Run("author and original amount", func(t *testing.T) {
+		// act
+		parsed, err := ParseNote("75.00 - taxi (USD 15.00) (@Rui)")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "Rui", parsed.Author)
+		_ = assert.Equal(t, "USD", parsed.Currency)
+		_ = assert.Equal(t, "taxi", parsed.Description)
+	})
+
+	_ = t.Run("original amount without author", func(t *testing.T) {
+		// act
+		parsed, err := ParseNote("75.00 - taxi (USD 15.00)")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Empty(t, parsed.Author)
+		_ = assert.Equal(t, "USD", parsed.Currency)
+	})
+
+	_ = t.Run("parenthetical typed by hand is not an author", func(t *testing.T) {
+		// act
+		parsed, err := ParseNote("45.00 - mercado (promo)")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Empty(t, parsed.Author)
+		_ = assert.Equal(t, "mercado (promo)", parsed.Description)
+	})
+}
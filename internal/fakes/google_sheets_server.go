@@ -0,0 +1,390 @@
+package fakes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+var cellRegex = regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+
+// GoogleSheetsServer is an in-memory fake of the subset of the Sheets v4 REST API the bot uses:
+// spreadsheets.get (with grid data and notes), values.get, values.update and batchUpdate of notes.
+type GoogleSheetsServer struct {
+	*httptest.Server
+	spreadsheetId string
+	mutex         sync.Mutex
+	sheets        []*fakeSheet
+}
+
+type fakeSheet struct {
+	id    int64
+	title string
+	cells map[cellKey]*fakeCell
+}
+
+type cellKey struct {
+	row    int
+	column int
+}
+
+type fakeCell struct {
+	value string
+	note  string
+}
+
+// gridRange is a zero-based, end-exclusive rectangle in a sheet, like sheets.GridRange.
+type gridRange struct {
+	sheet       *fakeSheet
+	startRow    int
+	endRow      int
+	startColumn int
+	endColumn   int
+}
+
+func NewGoogleSheetsServer(t *testing.T, spreadsheetId string) *GoogleSheetsServer {
+	t.Helper()
+
+	gs := &GoogleSheetsServer{
+		spreadsheetId: spreadsheetId,
+	}
+	gs.Server = httptest.NewServer(http.HandlerFunc(gs.handle))
+	t.Cleanup(gs.Close)
+
+	return gs
+}
+
+// Service returns a sheets service pointed at the fake server.
+func (gs *GoogleSheetsServer) Service(ctx context.Context) (*sheets.Service, error) {
+	return sheets.NewService(ctx,
+		option.WithEndpoint(gs.URL+"/"),
+		option.WithHTTPClient(gs.Client()),
+	)
+}
+
+func (gs *GoogleSheetsServer) AddSheet(title string) int64 {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	sheet := &fakeSheet{
+		id:    int64(len(gs.sheets) + 1),
+		title: title,
+		cells: make(map[cellKey]*fakeCell),
+	}
+	gs.sheets = append(gs.sheets, sheet)
+	return sheet.id
+}
+
+// SetValue sets the formatted value of a cell given in A1 notation, e.g. "2026!J14".
+func (gs *GoogleSheetsServer) SetValue(a1Range, value string) error {
+	return gs.withCell(a1Range, func(cell *fakeCell) { cell.value = value })
+}
+
+func (gs *GoogleSheetsServer) SetNote(a1Range, note string) error {
+	return gs.withCell(a1Range, func(cell *fakeCell) { cell.note = note })
+}
+
+func (gs *GoogleSheetsServer) Value(a1Range string) string {
+	var value string
+	_ = gs.withCell(a1Range, func(cell *fakeCell) { value = cell.value })
+	return value
+}
+
+func (gs *GoogleSheetsServer) Note(a1Range string) string {
+	var note string
+	_ = gs.withCell(a1Range, func(cell *fakeCell) { note = cell.note })
+	return note
+}
+
+func (gs *GoogleSheetsServer) withCell(a1Range string, fn func(cell *fakeCell)) error {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	r, err := gs.parseRange(a1Range)
+	if err != nil {
+		return err
+	}
+	fn(r.sheet.cell(r.startRow, r.startColumn))
+	return nil
+}
+
+func (gs *GoogleSheetsServer) handle(w http.ResponseWriter, r *http.Request) {
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+
+	prefix := "/v4/spreadsheets/" + gs.spreadsheetId
+	path, found := strings.CutPrefix(r.URL.Path, prefix)
+	if !found {
+		writeError(w, http.StatusNotFound, "Requested entity was not found.")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		gs.getSpreadsheet(w, r)
+	case r.Method == http.MethodPost && path == ":batchUpdate":
+		gs.batchUpdate(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/values/"):
+		gs.getValues(w, strings.TrimPrefix(path, "/values/"))
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/values/"):
+		gs.updateValues(w, r, strings.TrimPrefix(path, "/values/"))
+	default:
+		writeError(w, http.StatusNotImplemented, fmt.Sprintf("%s %s is not supported by the fake", r.Method, r.URL.Path))
+	}
+}
+
+func (gs *GoogleSheetsServer) getSpreadsheet(w http.ResponseWriter, r *http.Request) {
+	includeGridData := r.URL.Query().Get("includeGridData") == "true"
+
+	var ranges []gridRange
+	for _, a1Range := range r.URL.Query()["ranges"] {
+		parsed, err := gs.parseRange(a1Range)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ranges = append(ranges, parsed)
+	}
+
+	response := &sheets.Spreadsheet{SpreadsheetId: gs.spreadsheetId}
+	for _, sheet := range gs.sheets {
+		s := &sheets.Sheet{
+			Properties: &sheets.SheetProperties{SheetId: sheet.id, Title: sheet.title},
+		}
+		if includeGridData {
+			for _, gr := range ranges {
+				if gr.sheet == sheet {
+					s.Data = append(s.Data, gr.gridData())
+				}
+			}
+		}
+		response.Sheets = append(response.Sheets, s)
+	}
+
+	writeJSON(w, response)
+}
+
+func (gs *GoogleSheetsServer) getValues(w http.ResponseWriter, a1Range string) {
+	gr, err := gs.parseRange(a1Range)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, &sheets.ValueRange{
+		Range:          a1Range,
+		MajorDimension: "ROWS",
+		Values:         gr.values(),
+	})
+}
+
+func (gs *GoogleSheetsServer) updateValues(w http.ResponseWriter, r *http.Request, a1Range string) {
+	gr, err := gs.parseRange(a1Range)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body sheets.ValueRange
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updatedCells := 0
+	for i, row := range body.Values {
+		for j, value := range row {
+			gr.sheet.cell(gr.startRow+i, gr.startColumn+j).value = fmt.Sprint(value)
+			updatedCells++
+		}
+	}
+
+	writeJSON(w, &sheets.UpdateValuesResponse{
+		SpreadsheetId: gs.spreadsheetId,
+		UpdatedRange:  a1Range,
+		UpdatedCells:  int64(updatedCells),
+	})
+}
+
+func (gs *GoogleSheetsServer) batchUpdate(w http.ResponseWriter, r *http.Request) {
+	var body sheets.BatchUpdateSpreadsheetRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, request := range body.Requests {
+		updateCells := request.UpdateCells
+		if updateCells == nil || updateCells.Range == nil || updateCells.Fields != "note" {
+			writeError(w, http.StatusNotImplemented, "only updateCells requests on the note field are supported by the fake")
+			return
+		}
+
+		sheet := gs.sheetById(updateCells.Range.SheetId)
+		if sheet == nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("No grid with id: %d", updateCells.Range.SheetId))
+			return
+		}
+
+		for i, row := range updateCells.Rows {
+			for j, value := range row.Values {
+				sheet.cell(int(updateCells.Range.StartRowIndex)+i, int(updateCells.Range.StartColumnIndex)+j).note = value.Note
+			}
+		}
+	}
+
+	writeJSON(w, &sheets.BatchUpdateSpreadsheetResponse{SpreadsheetId: gs.spreadsheetId})
+}
+
+func (gs *GoogleSheetsServer) sheetById(id int64) *fakeSheet {
+	for _, sheet := range gs.sheets {
+		if sheet.id == id {
+			return sheet
+		}
+	}
+	return nil
+}
+
+func (gs *GoogleSheetsServer) sheetByTitle(title string) *fakeSheet {
+	for _, sheet := range gs.sheets {
+		if sheet.title == title {
+			return sheet
+		}
+	}
+	return nil
+}
+
+// parseRange parses A1 notation ranges such as "2026!J14" or "2026!A3:F33".
+func (gs *GoogleSheetsServer) parseRange(a1Range string) (gridRange, error) {
+	title, cells, found := strings.Cut(a1Range, "!")
+	if !found {
+		return gridRange{}, fmt.Errorf("Unable to parse range: %s", a1Range)
+	}
+
+	sheet := gs.sheetByTitle(strings.Trim(title, "'"))
+	if sheet == nil {
+		return gridRange{}, fmt.Errorf("Unable to parse range: %s", a1Range)
+	}
+
+	start, end, isRectangle := strings.Cut(cells, ":")
+	if !isRectangle {
+		end = start
+	}
+
+	startRow, startColumn, err := parseCell(start)
+	if err != nil {
+		return gridRange{}, fmt.Errorf("Unable to parse range: %s", a1Range)
+	}
+	endRow, endColumn, err := parseCell(end)
+	if err != nil {
+		return gridRange{}, fmt.Errorf("Unable to parse range: %s", a1Range)
+	}
+
+	return gridRange{
+		sheet:       sheet,
+		startRow:    startRow,
+		endRow:      endRow + 1,
+		startColumn: startColumn,
+		endColumn:   endColumn + 1,
+	}, nil
+}
+
+func (gr gridRange) values() [][]interface{} {
+	var values [][]interface{}
+	for row := gr.startRow; row < gr.endRow; row++ {
+		var rowValues []interface{}
+		for column := gr.startColumn; column < gr.endColumn; column++ {
+			rowValues = append(rowValues, gr.sheet.value(row, column))
+		}
+		// like the real API, trailing empty cells and rows are omitted
+		for len(rowValues) > 0 && rowValues[len(rowValues)-1] == "" {
+			rowValues = rowValues[:len(rowValues)-1]
+		}
+		values = append(values, rowValues)
+	}
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+func (gr gridRange) gridData() *sheets.GridData {
+	data := &sheets.GridData{
+		StartRow:    int64(gr.startRow),
+		StartColumn: int64(gr.startColumn),
+	}
+	for row := gr.startRow; row < gr.endRow; row++ {
+		rowData := &sheets.RowData{}
+		for column := gr.startColumn; column < gr.endColumn; column++ {
+			cellData := &sheets.CellData{}
+			if cell, ok := gr.sheet.cells[cellKey{row: row, column: column}]; ok {
+				cellData.FormattedValue = cell.value
+				cellData.Note = cell.note
+			}
+			rowData.Values = append(rowData.Values, cellData)
+		}
+		data.RowData = append(data.RowData, rowData)
+	}
+	return data
+}
+
+func (fs *fakeSheet) cell(row, column int) *fakeCell {
+	key := cellKey{row: row, column: column}
+	if _, ok := fs.cells[key]; !ok {
+		fs.cells[key] = &fakeCell{}
+	}
+	return fs.cells[key]
+}
+
+func (fs *fakeSheet) value(row, column int) string {
+	if cell, ok := fs.cells[cellKey{row: row, column: column}]; ok {
+		return cell.value
+	}
+	return ""
+}
+
+// parseCell converts a cell such as "J14" to zero-based row and column indexes.
+func parseCell(cell string) (int, int, error) {
+	matches := cellRegex.FindStringSubmatch(strings.ToUpper(cell))
+	if matches == nil {
+		return 0, 0, fmt.Errorf("invalid cell %q", cell)
+	}
+
+	column := 0
+	for _, letter := range matches[1] {
+		column = column*26 + int(letter-'A'+1)
+	}
+
+	row, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return row - 1, column - 1, nil
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+const (
+	interval       = time.Second / 2 // Check for new messages every 0.5 seconds
+	reminderHour   = 20              // 20 PM
+	reminderMinute = 30              // 30 minutes
+
+	recurringInterval = time.Minute // Check for due recurring transactions every minute
+)
+
+type BotService struct {
+	context        context.Context
+	appConfig      *configuration.ApplicationConfig
+	messageService *MessageService
+	transports     []Transport
+	// bindings route a chat to its own message service, other chats use messageService
+	bindings map[string]*MessageService
+	// schedules enables the reminder, recurring and digest messages, only the chat bot sends them
+	schedules bool
+	// replies are serialized so concurrent transports never interleave sheet updates
+	mutex sync.Mutex
+}
+
+func NewBotService(ctx context.Context, appConfig *configuration.ApplicationConfig, ms *MessageService, transports ...Transport) *BotService {
+	return &BotService{
+		context:        ctx,
+		appConfig:      appConfig,
+		messageService: ms,
+		transports:     transports,
+		bindings:       make(map[string]*MessageService),
+	}
+}
+
+// Bind routes the messages of a chat, and its scheduled messages, to the given message service
+func (bs *BotService) Bind(chatID string, ms *MessageService) {
+	bs.bindings[chatID] = ms
+}
+
+// EnableSchedules sends the daily reminder, the due recurring transactions and the digests while running
+func (bs *BotService) EnableSchedules() {
+	bs.schedules = true
+}
+
+func (bs *BotService) Run() {
+	var wg sync.WaitGroup
+	for _, transport := range bs.transports {
+		if err := transport.Open(bs.context); err != nil {
+			log.Fatalf("error opening %s transport: %v", transport.Name(), err)
+		}
+		defer transport.Close()
+
+		wg.Add(1)
+		go func(transport Transport) {
+			defer wg.Done()
+			bs.listen(transport)
+		}(transport)
+	}
+
+	if bs.schedules {
+		bs.startSchedules()
+	}
+	wg.Wait()
+}
+
+func (bs *BotService) startSchedules() {
+	bs.scheduledDailyReminder()
+	bs.scheduledRecurringTransactions()
+	if weekly := bs.appConfig.Digests.Weekly; weekly.IsEnabled {
+		bs.scheduledDigest("weekly digest", func(now time.Time) time.Time {
+			return nextWeeklyDigest(now, time.Weekday(weekly.Weekday), weekly.Hour, weekly.Minute)
+		}, (*MessageService).GetWeeklyDigest)
+	}
+	if monthly := bs.appConfig.Digests.Monthly; monthly.IsEnabled {
+		bs.scheduledDigest("monthly digest", func(now time.Time) time.Time {
+			return nextMonthlyDigest(now, monthly.Hour, monthly.Minute)
+		}, (*MessageService).GetMonthlyDigest)
+	}
+}
+
+func (bs *BotService) listen(transport Transport) {
+	log.Infof("listening for messages on %s...", transport.Name())
+	for bs.context.Err() == nil {
+		err := bs.handleMessages(transport)
+		if errors.Is(err, io.EOF) {
+			log.Infof("%s transport closed", transport.Name())
+			return
+		}
+		if err != nil {
+			log.Errorf("error handling %s messages: %v", transport.Name(), err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (bs *BotService) handleMessages(transport Transport) error {
+	messages, err := transport.Receive(bs.context)
+	if err != nil {
+		return err
+	}
+
+	acknowledger, _ := transport.(Acknowledger)
+	for _, message := range messages {
+		err := bs.reply(transport, message)
+		// the message was processed even when the reply failed, delivering it again would record it twice
+		if acknowledger != nil {
+			acknowledger.Ack(message)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bs *BotService) reply(transport Transport, message *domain.Message) error {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	log.Info("processing message: ", message.Message)
+	response := bs.messageServiceFor(message.ChatID).ProcessAndReply(message)
+	if response == nil {
+		return nil
+	}
+
+	if response.Attachment != "" {
+		return bs.replyWithAttachment(transport, message, response)
+	}
+
+	if err := transport.Reply(bs.context, message, response); err != nil {
+		return err
+	}
+	log.Info("message processed: ", response.Message)
+	return nil
+}
+
+// replyWithAttachment attaches the file when the transport can, otherwise the file path is sent along the text
+func (bs *BotService) replyWithAttachment(transport Transport, message *domain.Message, response *domain.Message) error {
+	if fileSender, ok := transport.(FileSender); ok {
+		err := fileSender.SendFile(bs.context, message.ChatID, response.Attachment, response.Message)
+		if err == nil {
+			log.Info("message processed with attachment: ", response.Message)
+			return nil
+		}
+		log.Errorf("error attaching file on %s, sending its path instead: %v", transport.Name(), err)
+	}
+
+	return transport.Reply(bs.context, message, &domain.Message{
+		Message: fmt.Sprintf("%s (saved to %s)", response.Message, response.Attachment),
+	})
+}
+
+// broadcast posts a message to every chat routed to the message service
+func (bs *BotService) broadcast(ms *MessageService, message *domain.Message) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	for _, transport := range bs.transports {
+		for _, chatID := range transport.Chats() {
+			if bs.messageServiceFor(chatID) != ms {
+				continue
+			}
+			if err := transport.Send(bs.context, chatID, message); err != nil {
+				log.Errorf("error sending message to %s chat %s: %v", transport.Name(), chatID, err)
+			}
+		}
+	}
+}
+
+func (bs *BotService) scheduledDailyReminder() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("panic recovered in scheduledDailyReminder")
+			}
+		}()
+
+		for {
+			now := time.Now()
+			nextReminder := time.Date(now.Year(), now.Month(), now.Day(), reminderHour, reminderMinute, 0, 0, now.Location())
+			if now.After(nextReminder) {
+				nextReminder = nextReminder.Add(24 * time.Hour)
+			}
+			time.Sleep(time.Until(nextReminder))
+
+			for _, ms := range bs.messageServices() {
+				reminderMessage := ms.GetDailyReminder()
+				if reminderMessage != "" && reminderMessage != domain.InvalidMessage {
+					log.Info("sending daily reminder...")
+					bs.broadcast(ms, &domain.Message{
+						Message: reminderMessage,
+					})
+				}
+			}
+		}
+	}()
+}
+
+func (bs *BotService) scheduledRecurringTransactions() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("panic recovered in scheduledRecurringTransactions")
+			}
+		}()
+
+		for {
+			for _, ms := range bs.messageServices() {
+				for _, announcement := range ms.ProcessDueRecurring(time.Now()) {
+					log.Info("announcing recurring transaction...")
+					bs.broadcast(ms, announcement)
+				}
+			}
+			time.Sleep(recurringInterval)
+		}
+	}()
+}
+
+// scheduledDigest posts the digest built by each message service whenever next says it is due
+func (bs *BotService) scheduledDigest(name string, next func(now time.Time) time.Time, digest func(ms *MessageService, now time.Time) string) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("panic recovered in scheduled %s", name)
+			}
+		}()
+
+		for {
+			time.Sleep(time.Until(next(time.Now())))
+
+			now := time.Now()
+			for _, ms := range bs.messageServices() {
+				if message := digest(ms, now); message != "" {
+					log.Infof("sending %s...", name)
+					bs.broadcast(ms, &domain.Message{
+						Message: message,
+					})
+				}
+			}
+		}
+	}()
+}
+
+func nextWeeklyDigest(now time.Time, weekday time.Weekday, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	next = next.AddDate(0, 0, (int(weekday)-int(now.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+func nextMonthlyDigest(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), 1, hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 1, 0)
+	}
+	return next
+}
+
+func (bs *BotService) messageServiceFor(chatID string) *MessageService {
+	if ms, ok := bs.bindings[chatID]; ok {
+		return ms
+	}
+	return bs.messageService
+}
+
+// messageServices returns the default message service followed by the bound ones, without repetition
+func (bs *BotService) messageServices() []*MessageService {
+	messageServices := []*MessageService{bs.messageService}
+	seen := map[*MessageService]bool{bs.messageService: true}
+	for _, transport := range bs.transports {
+		for _, chatID := range transport.Chats() {
+			ms := bs.messageServiceFor(chatID)
+			if !seen[ms] {
+				seen[ms] = true
+				messageServices = append(messageServices, ms)
+			}
+		}
+	}
+	return messageServices
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+// fakeTransport delivers its inbound messages once and then reports it was closed
+type fakeTransport struct {
+	inbound   []*domain.Message
+	delivered bool
+	replies   map[string][]string
+}
+
+func (ft *fakeTransport) Name() string {
+	return "fake"
+}
+
+func (ft *fakeTransport) Open(_ context.Context) error {
+	ft.replies = make(map[string][]string)
+	return nil
+}
+
+func (ft *fakeTransport) Receive(_ context.Context) ([]*domain.Message, error) {
+	if ft.delivered {
+		return nil, io.EOF
+	}
+	ft.delivered = true
+	return ft.inbound, nil
+}
+
+func (ft *fakeTransport) Reply(_ context.Context, to *domain.Message, reply *domain.Message) error {
+	ft.replies[to.ChatID] = append(ft.replies[to.ChatID], reply.Message)
+	return nil
+}
+
+func (ft *fakeTransport) Send(_ context.Context, chatID string, message *domain.Message) error {
+	ft.replies[chatID] = append(ft.replies[chatID], message.Message)
+	return nil
+}
+
+func (ft *fakeTransport) Chats() []string {
+	return nil
+}
+
+func (ft *fakeTransport) Close() error {
+	return nil
+}
+
+// fakeFileTransport is a fakeTransport that can attach files
+type fakeFileTransport struct {
+	fakeTransport
+	files map[string][]string
+}
+
+func (fft *fakeFileTransport) SendFile(_ context.Context, chatID string, path string, caption string) error {
+	if fft.files == nil {
+		fft.files = make(map[string][]string)
+	}
+	fft.files[chatID] = append(fft.files[chatID], path+" "+caption)
+	return nil
+}
+
+func TestBotService_Bindings(t *testing.T) {
+
+	_ = t.Run("routes each chat to its message service", func(t *testing.T) {
+		// arrange
+		homeLedger := &fakeLedger{}
+		beachLedger := &fakeLedger{}
+		transport := &fakeTransport{inbound: []*domain.Message{
+			{ChatID: "casa", Message: "-45 / mercado"},
+			{ChatID: "praia", Message: "-20 / sorvete"},
+			{ChatID: "telegram", Message: "-10 / pao"},
+		}}
+		bs := NewBotService(context.Background(), &configuration.ApplicationConfig{}, newTestMessageService(t, homeLedger), transport)
+		bs.Bind("praia", newTestMessageService(t, beachLedger))
+
+		// act
+		bs.Run()
+
+		// assert
+		_ = assert.Len(t, homeLedger.transactions, 2)
+		_ = assert.Len(t, beachLedger.transactions, 1)
+		_ = assert.Equal(t, "sorvete", beachLedger.transactions[0].Description)
+		_ = assert.Equal(t, []string{"sys: processed -20 / sorvete"}, transport.replies["praia"])
+	})
+}
+
+func TestBotService_DigestSchedules(t *testing.T) {
+
+	_ = t.Run("weekly digest on the next sunday evening", func(t *testing.T) {
+		// arrange
+		wednesday := time.Date(2026, 3, 11, 10, 0, 0, 0, time.Local)
+		sundayNight := time.Date(2026, 3, 15, 21, 0, 0, 0, time.Local)
+
+		// act
+		fromWednesday := nextWeeklyDigest(wednesday, time.Sunday, 20, 0)
+		fromSundayNight := nextWeeklyDigest(sundayNight, time.Sunday, 20, 0)
+
+		// assert
+		_ = assert.Equal(t, time.Date(2026, 3, 15, 20, 0, 0, 0, time.Local), fromWednesday)
+		_ = assert.Equal(t, time.Date(2026, 3, 22, 20, 0, 0, 0, time.Local), fromSundayNight)
+	})
+
+	_ = t.Run("monthly digest on the first day", func(t *testing.T) {
+		// arrange
+		firstMorning := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+		midMonth := time.Date(2026, 12, 12, 10, 0, 0, 0, time.Local)
+
+		// act
+		fromFirstMorning := nextMonthlyDigest(firstMorning, 9, 0)
+		fromMidMonth := nextMonthlyDigest(midMonth, 9, 0)
+
+		// assert
+		_ = assert.Equal(t, time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local), fromFirstMorning)
+		_ = assert.Equal(t, time.Date(2027, 1, 1, 9, 0, 0, 0, time.Local), fromMidMonth)
+	})
+}
+
+func TestBotService_Attachments(t *testing.T) {
+	newChartMessageService := func(t *testing.T) *MessageService {
+		ms := newTestMessageService(t, &fakeLedger{summaries: map[string]*domain.MonthSummary{
+			"03/2025": {Outcome: 10, DailyOutcomes: []float64{10}},
+		}})
+		ms.appConfig.Charts.Dir = t.TempDir()
+		return ms
+	}
+
+	_ = t.Run("attaches the chart when the transport can", func(t *testing.T) {
+		// arrange
+		ms := newChartMessageService(t)
+		transport := &fakeFileTransport{fakeTransport: fakeTransport{inbound: []*domain.Message{
+			{ChatID: "casa", Message: "grafico 03/2025"},
+		}}}
+		bs := NewBotService(context.Background(), &configuration.ApplicationConfig{}, ms, transport)
+
+		// act
+		bs.Run()
+
+		// assert
+		_ = assert.Empty(t, transport.replies["casa"])
+		_ = assert.Equal(t, []string{filepath.Join(ms.appConfig.Charts.Dir, "grafico-2025-03.png") + " sys: chart 03/2025, outcome R$ 10,00"},
+			transport.files["casa"])
+	})
+
+	_ = t.Run("sends the chart path otherwise", func(t *testing.T) {
+		// arrange
+		ms := newChartMessageService(t)
+		transport := &fakeTransport{inbound: []*domain.Message{
+			{ChatID: "casa", Message: "grafico 03/2025"},
+		}}
+		bs := NewBotService(context.Background(), &configuration.ApplicationConfig{}, ms, transport)
+
+		// act
+		bs.Run()
+
+		// assert
+		_ = assert.Equal(t, []string{"sys: chart 03/2025, outcome R$ 10,00 (saved to " + filepath.Join(ms.appConfig.Charts.Dir, "grafico-2025-03.png") + ")"},
+			transport.replies["casa"])
+	})
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+type BudgetService struct {
+	appConfig *configuration.ApplicationConfig
+	// budgets set from chat, persisted in the budgets file
+	chatLimits *overrideStore
+}
+
+func NewBudgetService(appConfig *configuration.ApplicationConfig) (*BudgetService, error) {
+	chatLimits, err := newOverrideStore("budgets", &appConfig.Budgets.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BudgetService{
+		appConfig:  appConfig,
+		chatLimits: chatLimits,
+	}, nil
+}
+
+// Budgets returns the monthly limit per category, chat budgets override the configured ones
+func (bs *BudgetService) Budgets() map[string]float64 {
+	return bs.chatLimits.merge(bs.appConfig.Budgets.Limits, strings.ToLower)
+}
+
+func (bs *BudgetService) Budget(category string) (float64, bool) {
+	limit, ok := bs.Budgets()[category]
+	return limit, ok
+}
+
+func (bs *BudgetService) SetBudget(budget *domain.Budget) error {
+	return bs.chatLimits.set(budget.Category, budget.Limit)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = time.Minute
+)
+
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"
+	circuitOpen     circuitState = "open"
+	circuitHalfOpen circuitState = "half-open"
+)
+
+// circuitBreaker stops calling an unhealthy dependency for a cooldown after consecutive failures,
+// then lets a single trial call decide whether it is back
+type circuitBreaker struct {
+	name             string
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+
+	mutex    sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(name string, failureThreshold int, cooldown time.Duration) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = defaultFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultCooldown
+	}
+	return &circuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		now:              time.Now,
+		state:            circuitClosed,
+	}
+}
+
+// Allow tells whether a call may be made, an open circuit becomes half-open once the cooldown is over
+func (cb *circuitBreaker) Allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.transition(circuitHalfOpen)
+		return true
+	case circuitHalfOpen:
+		// the trial call is still running
+		return false
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreaker) Success() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.failures = 0
+	if cb.state != circuitClosed {
+		cb.transition(circuitClosed)
+	}
+}
+
+func (cb *circuitBreaker) Failure() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || (cb.state == circuitClosed && cb.failures >= cb.failureThreshold) {
+		cb.openedAt = cb.now()
+		cb.transition(circuitOpen)
+	}
+}
+
+func (cb *circuitBreaker) transition(state circuitState) {
+	if state == circuitOpen {
+		log.Warnf("%s circuit %s -> %s after %d failures, bypassing it for %s", cb.name, cb.state, state, cb.failures, cb.cooldown)
+	} else {
+		log.Infof("%s circuit %s -> %s", cb.name, cb.state, state)
+	}
+	cb.state = state
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	newBreaker := func(now *time.Time) *circuitBreaker {
+		cb := newCircuitBreaker("test", 2, time.Minute)
+		cb.now = func() time.Time { return *now }
+		return cb
+	}
+
+	_ = t.Run("opens after consecutive failures", func(t *testing.T) {
+		// arrange
+		now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+		cb := newBreaker(&now)
+
+		// act
+		cb.Failure()
+		allowedAfterOne := cb.Allow()
+		cb.Failure()
+
+		// assert
+		_ = assert.True(t, allowedAfterOne)
+		_ = assert.False(t, cb.Allow())
+		_ = assert.Equal(t, circuitOpen, cb.state)
+	})
+
+	_ = t.Run("a success resets the failures", func(t *testing.T) {
+		// arrange
+		now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+		cb := newBreaker(&now)
+
+		// act
+		cb.Failure()
+		cb.Success()
+		cb.Failure()
+
+		// assert
+		_ = assert.True(t, cb.Allow())
+		_ = assert.Equal(t, circuitClosed, cb.state)
+	})
+
+	_ = t.Run("lets a single trial through after the cooldown", func(t *testing.T) {
+		// arrange
+		now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+		cb := newBreaker(&now)
+		cb.Failure()
+		cb.Failure()
+		now = now.Add(time.Minute)
+
+		// act
+		trial := cb.Allow()
+		concurrent := cb.Allow()
+		cb.Success()
+
+		// assert
+		_ = assert.True(t, trial)
+		_ = assert.False(t, concurrent)
+		_ = assert.Equal(t, circuitClosed, cb.state)
+		_ = assert.True(t, cb.Allow())
+	})
+
+	_ = t.Run("a failed trial opens it again", func(t *testing.T) {
+		// arrange
+		now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+		cb := newBreaker(&now)
+		cb.Failure()
+		cb.Failure()
+		now = now.Add(time.Minute)
+
+		// act
+		_ = cb.Allow()
+		cb.Failure()
+
+		// assert
+		_ = assert.Equal(t, circuitOpen, cb.state)
+		_ = assert.False(t, cb.Allow())
+	})
+}
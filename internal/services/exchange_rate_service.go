@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+type ExchangeRateService struct {
+	appConfig *configuration.ApplicationConfig
+	// rates set from chat, persisted in the rates file
+	chatRates *overrideStore
+}
+
+func NewExchangeRateService(appConfig *configuration.ApplicationConfig) (*ExchangeRateService, error) {
+	chatRates, err := newOverrideStore("rates", &appConfig.Currency.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExchangeRateService{
+		appConfig: appConfig,
+		chatRates: chatRates,
+	}, nil
+}
+
+// Rates returns the value of one unit of each currency in BRL, chat rates override the configured ones
+func (ers *ExchangeRateService) Rates() map[string]float64 {
+	return ers.chatRates.merge(ers.appConfig.Currency.Rates, strings.ToUpper)
+}
+
+func (ers *ExchangeRateService) Rate(currency string) (float64, bool) {
+	rate, ok := ers.Rates()[currency]
+	return rate, ok
+}
+
+// Convert converts a transaction given in a foreign currency to BRL, failing with domain.ErrInvalidExchangeRate
+// when there is no rate for it
+func (ers *ExchangeRateService) Convert(transaction *domain.Transaction) error {
+	if transaction.Currency == "" {
+		return nil
+	}
+
+	rate, ok := ers.Rate(transaction.Currency)
+	if !ok {
+		return domain.ErrInvalidExchangeRate
+	}
+
+	transaction.Convert(rate)
+	return nil
+}
+
+func (ers *ExchangeRateService) SetRate(exchangeRate *domain.ExchangeRate) error {
+	return ers.chatRates.set(exchangeRate.Currency, exchangeRate.Rate)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var exportHeader = []string{"date", "direction", "amount", "description", "category", "author"}
+
+type ExportService struct {
+	ledger Ledger
+}
+
+func NewExportService(ledger Ledger) *ExportService {
+	return &ExportService{
+		ledger: ledger,
+	}
+}
+
+// WriteCSV writes the transactions between from and to, both days included, and returns how many were written
+func (es *ExportService) WriteCSV(w io.Writer, from, to time.Time) (int, error) {
+	transactions, err := es.ledger.Transactions(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return 0, err
+	}
+
+	for _, transaction := range transactions {
+		direction := outcomeDirection
+		amount := -transaction.Amount
+		if transaction.IsIncome() {
+			direction, amount = incomeDirection, transaction.Amount
+		}
+
+		err := writer.Write([]string{
+			transaction.Date.Format(dayLayout),
+			direction,
+			strconv.FormatFloat(amount, 'f', 2, 64),
+			transaction.Description,
+			transaction.Category,
+			transaction.Author,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+	return len(transactions), writer.Error()
+}
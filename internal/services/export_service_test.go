@@ -0,0 +1,34 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func TestExportService_WriteCSV(t *testing.T) {
+	today := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("one row per transaction", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: 1000, Description: "salario", Date: today.AddDate(0, 0, -11)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -45.9, Description: "mercado, feira", Category: "alimentacao", Author: "Ana"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao", Date: today.AddDate(0, -3, 0)})
+		var csv strings.Builder
+
+		// act
+		count, err := NewExportService(ledger).WriteCSV(&csv, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), today)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 2, count)
+		_ = assert.Equal(t, "date,direction,amount,description,category,author\n"+
+			"2026-03-01,income,1000.00,salario,,\n"+
+			"2026-03-12,outcome,45.90,\"mercado, feira\",alimentacao,Ana\n", csv.String())
+	})
+}
@@ -5,6 +5,10 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
 
 	"github.com/vitortenor/sheet-bot/internal/client"
 	"github.com/vitortenor/sheet-bot/internal/configuration"
@@ -12,175 +16,460 @@ import (
 	"github.com/vitortenor/sheet-bot/internal/utils"
 )
 
-const (
-	ZeroBalance = domain.ZeroBalanceMessage
-	SystemError = domain.SystemErrorMessage
-)
+var _ Ledger = (*GoogleSheetsService)(nil)
 
 type GoogleSheetsService struct {
 	appConfig *configuration.ApplicationConfig
 	client    *client.GoogleSheetsClient
+	// writes read the current cell before updating it, so they must not interleave
+	mutex sync.Mutex
+	now   func() time.Time
+	// lastRecorded is what the last transaction left in its cell, it is cleared once undone
+	lastRecorded *recordedTransaction
+	// dailyDefaults keeps the diario values replaced by the first outcome of a day, by cell range
+	dailyDefaults map[string]string
+}
+
+type recordedTransaction struct {
+	transaction *domain.Transaction
+	date        time.Time
+	sheetId     int64
+	cellRange   string
+	row         int
+	column      int
+	value       float64
+	note        string
+	// previousValue is the cell as it was before the write, restored when the undo leaves no notes
+	previousValue string
 }
 
 func NewGoogleSheetsService(appConfig *configuration.ApplicationConfig, gsc *client.GoogleSheetsClient) *GoogleSheetsService {
 	return &GoogleSheetsService{
-		appConfig: appConfig,
-		client:    gsc,
+		appConfig:     appConfig,
+		client:        gsc,
+		now:           time.Now,
+		dailyDefaults: make(map[string]string),
 	}
 }
 
-func (gss *GoogleSheetsService) GetDailyExpenses() string {
-	_, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+func (gss *GoogleSheetsService) DailyOutcome() (float64, error) {
+	return gss.getCurrentYearValue(utils.BuildDailyOutcomeRange(gss.appConfig.Layout, gss.now()))
+}
+
+func (gss *GoogleSheetsService) Balance() (float64, error) {
+	return gss.getCurrentYearValue(utils.BuildBalanceRange(gss.appConfig.Layout, gss.now()))
+}
+
+func (gss *GoogleSheetsService) DailyNotes() ([]string, error) {
+	sheetId, err := gss.getCurrentYearSheetId()
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	valueRange := utils.BuildDailyOutcomeRange()
-	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, valueRange)
+	existingNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, sheetId, utils.BuildDailyOutcomeRange(gss.appConfig.Layout, gss.now()))
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	if len(response.Values) > 0 && len(response.Values[0]) > 0 {
-		return domain.SystemMessagePrefix + response.Values[0][0].(string)
+	if existingNote == "" {
+		return nil, nil
 	}
 
-	return ZeroBalance
+	return strings.Split(existingNote, "\n"), nil
 }
 
-func (gss *GoogleSheetsService) GetBalance() string {
-	_, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+func (gss *GoogleSheetsService) RemoveDailyNote(index int) (*domain.Transaction, error) {
+	gss.mutex.Lock()
+	defer gss.mutex.Unlock()
+
+	today := gss.now()
+	var removed *domain.Transaction
+	err := gss.rewriteDailyNotes(today, func(notes []string) ([]string, error) {
+		if index < 1 || index > len(notes) {
+			return nil, domain.ErrNoteNotFound
+		}
+
+		var err error
+		removed, err = domain.ParseNote(notes[index-1])
+		if err != nil {
+			return nil, err
+		}
+
+		return append(notes[:index-1], notes[index:]...), nil
+	})
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, utils.BuildBalanceRange())
-	if err != nil {
-		return SystemError
+	gss.adjustCategoryTotal(today, removed.Category, -removed.Amount)
+	return removed, nil
+}
+
+func (gss *GoogleSheetsService) EditDailyNote(index int, transaction *domain.Transaction) (*domain.Transaction, error) {
+	if err := transaction.Validate(); err != nil {
+		return nil, err
 	}
 
-	if len(response.Values) > 0 && len(response.Values[0]) > 0 {
-		return domain.SystemMessagePrefix + response.Values[0][0].(string)
+	gss.mutex.Lock()
+	defer gss.mutex.Unlock()
+
+	today := gss.now()
+	var replaced *domain.Transaction
+	err := gss.rewriteDailyNotes(today, func(notes []string) ([]string, error) {
+		if index < 1 || index > len(notes) {
+			return nil, domain.ErrNoteNotFound
+		}
+
+		var err error
+		replaced, err = domain.ParseNote(notes[index-1])
+		if err != nil {
+			return nil, err
+		}
+
+		notes[index-1] = transaction.Note()
+		return notes, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return ZeroBalance
+	gss.adjustCategoryTotal(today, replaced.Category, -replaced.Amount)
+	gss.adjustCategoryTotal(today, transaction.Category, math.Abs(transaction.Amount))
+	return replaced, nil
 }
 
-func (gss *GoogleSheetsService) SetDailyAsZero() string {
-	sheetId, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+// rewriteDailyNotes replaces the day's notes and sets the daily outcome to their sum, so value and notes never drift apart,
+// once no note is left the default value comes back, or the cell is cleared when it is unknown, since 0 means a zeroed day
+func (gss *GoogleSheetsService) rewriteDailyNotes(today time.Time, rewrite func(notes []string) ([]string, error)) error {
+	sheetId, err := gss.getYearSheetId(today)
 	if err != nil {
-		return SystemError
+		return err
 	}
 
-	existingNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, sheetId, utils.BuildDailyOutcomeRange())
+	rowAndColumnRange := utils.BuildDailyOutcomeRange(gss.appConfig.Layout, today)
+	existingNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, sheetId, rowAndColumnRange)
 	if err != nil {
-		return SystemError
+		return err
 	}
 
+	var notes []string
 	if existingNote != "" {
-		return domain.SystemMessagePrefix + "daily value has notes, please remove them before setting as zero"
+		notes = strings.Split(existingNote, "\n")
+	}
+
+	notes, err = rewrite(notes)
+	if err != nil {
+		return err
+	}
+
+	total := 0.0
+	for _, note := range notes {
+		transaction, err := domain.ParseNote(note)
+		if err != nil {
+			return err
+		}
+		total += transaction.Amount
+	}
+
+	newValue := formatCellValue(total)
+	if len(notes) == 0 {
+		newValue = gss.dailyDefaults[rowAndColumnRange]
 	}
 
-	err = gss.client.UpdateSheet(gss.appConfig.Google.SheetId, utils.BuildDailyOutcomeRange(), []interface{}{"0"})
+	err = gss.client.UpdateSheet(gss.appConfig.Google.SheetId, rowAndColumnRange, []interface{}{newValue})
 	if err != nil {
-		return SystemError
+		return err
+	}
+	if len(notes) == 0 {
+		delete(gss.dailyDefaults, rowAndColumnRange)
 	}
 
-	return domain.SystemMessagePrefix + "daily value set as zero"
+	noteRequest := utils.BuildNoteRequest(strings.Join(notes, "\n"), sheetId,
+		utils.GetDayRow(gss.appConfig.Layout, today), utils.GetDailyOutcomeColumnNumber(gss.appConfig.Layout, today))
+	return gss.client.BatchUpdate(gss.appConfig.Google.SheetId, noteRequest)
 }
 
-func (gss *GoogleSheetsService) ProcessAndUpdateSheet(inputValue string) string {
-	sheetId, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+func (gss *GoogleSheetsService) CategoryTotals() (map[string]float64, error) {
+	sheetId, err := gss.getCurrentYearSheetId()
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	err = gss.updateSheetValuesAndNotes(sheetId, inputValue)
+	monthNotes, err := gss.client.GetNotes(gss.appConfig.Google.SheetId, sheetId, utils.BuildMonthDailyOutcomeRange(gss.appConfig.Layout, gss.now()))
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	return domain.SystemMessagePrefix + "processed " + inputValue
+	totals := make(map[string]float64)
+	for _, dayNotes := range monthNotes {
+		if dayNotes == "" {
+			continue
+		}
+		for _, note := range strings.Split(dayNotes, "\n") {
+			transaction, err := domain.ParseNote(note)
+			if err != nil {
+				log.Warn("ignoring note that could not be parsed: ", note)
+				continue
+			}
+			totals[transaction.Category] += transaction.Amount
+		}
+	}
+
+	return totals, nil
 }
 
-func (gss *GoogleSheetsService) GetDetailedDailyBalance() string {
-	sheetId, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+func (gss *GoogleSheetsService) MonthSummary(month time.Time) (*domain.MonthSummary, error) {
+	if _, err := gss.getYearSheetId(month); err != nil {
+		return nil, err
+	}
+
+	days := domain.ElapsedDays(month, gss.now())
+	incomes, err := gss.getDayValues(utils.BuildMonthIncomeRange(gss.appConfig.Layout, month), days)
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	existingNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, sheetId, utils.BuildDailyOutcomeRange())
+	outcomes, err := gss.getDayValues(utils.BuildMonthDailyOutcomeRange(gss.appConfig.Layout, month), days)
 	if err != nil {
-		return SystemError
+		return nil, err
 	}
 
-	if existingNote == "" {
-		return domain.SystemMessagePrefix + "\n" + existingNote
+	balances, err := gss.getDayValues(utils.BuildMonthBalanceRange(gss.appConfig.Layout, month), days)
+	if err != nil {
+		return nil, err
 	}
 
-	if strings.Contains(existingNote, "\n") {
-		notes := strings.Split(existingNote, "\n")
-		var formattedNotes []string
-		for _, note := range notes {
-			formattedNote := domain.SystemMessagePrefix + " " + note
-			formattedNotes = append(formattedNotes, formattedNote)
+	summary := &domain.MonthSummary{
+		Month:         month,
+		DailyOutcomes: outcomes,
+	}
+	for i := 0; i < days; i++ {
+		summary.Income += incomes[i]
+		summary.Outcome += outcomes[i]
+		// the balance of a day may be left blank, the last filled one closes the month
+		if balances[i] != 0 {
+			summary.ClosingBalance = balances[i]
 		}
+	}
+
+	return summary, nil
+}
+
+// getDayValues reads a month column, blank cells are read as zero
+func (gss *GoogleSheetsService) getDayValues(rowAndColumnRange string, days int) ([]float64, error) {
+	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, rowAndColumnRange)
+	if err != nil {
+		return nil, err
+	}
 
-		return strings.Join(formattedNotes, "\n")
+	values := make([]float64, days)
+	for i := 0; i < days && i < len(response.Values); i++ {
+		if len(response.Values[i]) == 0 {
+			continue
+		}
+		values[i], err = utils.ParseMoneyValue(fmt.Sprint(response.Values[i][0]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Transactions parses the entrada and diario notes of each day up to today, a value whose notes cannot be parsed
+// is returned as a single transaction described by its raw notes
+func (gss *GoogleSheetsService) Transactions(from, to time.Time) ([]*domain.Transaction, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+	// the days ahead only hold the default diario values
+	if now := gss.now(); to.After(now) {
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, to.Location())
+	}
+
+	var transactions []*domain.Transaction
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+		sheetId, err := gss.getYearSheetId(month)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", utils.GetSheetName(gss.appConfig.Layout, month), err)
+		}
+
+		days := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
+		incomes, incomeNotes, err := gss.getDayValuesAndNotes(sheetId, utils.BuildMonthIncomeRange(gss.appConfig.Layout, month), days)
+		if err != nil {
+			return nil, err
+		}
+		outcomes, outcomeNotes, err := gss.getDayValuesAndNotes(sheetId, utils.BuildMonthDailyOutcomeRange(gss.appConfig.Layout, month), days)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < days; i++ {
+			day := month.AddDate(0, 0, i)
+			if day.Before(from) || day.After(to) {
+				continue
+			}
+			transactions = append(transactions, parseDayTransactions(day, incomes[i], incomeNotes[i], 1)...)
+			transactions = append(transactions, parseDayTransactions(day, outcomes[i], outcomeNotes[i], -1)...)
+		}
 	}
 
-	return domain.SystemMessagePrefix + " " + existingNote
+	return transactions, nil
 }
 
-func (gss *GoogleSheetsService) GetDailyReminder() string {
-	sheetId, err := gss.client.GetSheetId(gss.appConfig.Google.SheetId, strconv.Itoa(utils.GetCurrentYear()))
+func (gss *GoogleSheetsService) getDayValuesAndNotes(sheetId int64, rowAndColumnRange string, days int) ([]float64, []string, error) {
+	values, err := gss.getDayValues(rowAndColumnRange, days)
 	if err != nil {
-		return SystemError
+		return nil, nil, err
 	}
 
-	existingNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, sheetId, utils.BuildDailyOutcomeRange())
+	notes, err := gss.client.GetNotes(gss.appConfig.Google.SheetId, sheetId, rowAndColumnRange)
 	if err != nil {
-		return SystemError
+		return nil, nil, err
+	}
+	// trailing rows without data are not returned
+	for len(notes) < days {
+		notes = append(notes, "")
 	}
 
-	if existingNote != "" {
-		return domain.InvalidMessage
+	return values, notes, nil
+}
+
+// parseDayTransactions turns a day's cell into transactions, sign is -1 for the diario column
+func parseDayTransactions(day time.Time, value float64, note string, sign float64) []*domain.Transaction {
+	// a diario value without notes is the sheet's default, nothing was logged that day
+	if note == "" && (value == 0 || sign < 0) {
+		return nil
+	}
+
+	var transactions []*domain.Transaction
+	if note != "" {
+		for _, line := range strings.Split(note, "\n") {
+			transaction, err := domain.ParseNote(line)
+			if err != nil {
+				transactions = nil
+				break
+			}
+			transaction.Amount *= sign
+			transaction.OriginalAmount *= sign
+			transaction.Date = day
+			transactions = append(transactions, transaction)
+		}
+	}
+	if transactions != nil {
+		return transactions
+	}
+
+	// values whose notes were typed by hand, or zeroed days
+	if value == 0 {
+		return nil
+	}
+	return []*domain.Transaction{{
+		Amount:      value * sign,
+		Description: strings.ReplaceAll(note, "\n", "; "),
+		Date:        day,
+	}}
+}
+
+func (gss *GoogleSheetsService) ZeroDaily() error {
+	gss.mutex.Lock()
+	defer gss.mutex.Unlock()
+
+	notes, err := gss.DailyNotes()
+	if err != nil {
+		return err
+	}
+
+	if len(notes) > 0 {
+		return domain.ErrDailyHasNotes
+	}
+
+	return gss.client.UpdateSheet(gss.appConfig.Google.SheetId, utils.BuildDailyOutcomeRange(gss.appConfig.Layout, gss.now()), []interface{}{"0"})
+}
+
+func (gss *GoogleSheetsService) NeedsDailyReminder() (bool, error) {
+	notes, err := gss.DailyNotes()
+	if err != nil {
+		return false, err
+	}
+
+	if len(notes) > 0 {
+		return false, nil
 	}
 
-	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, utils.BuildDailyOutcomeRange())
+	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, utils.BuildDailyOutcomeRange(gss.appConfig.Layout, gss.now()))
 	if err != nil {
-		return SystemError
+		return false, err
 	}
 
 	if len(response.Values) > 0 && len(response.Values[0]) > 0 {
-		currentValue := utils.CleanMoneyValue(response.Values[0][0].(string))
-		if currentValue != "0.00" {
-			return domain.SystemMessagePrefix + "you haven’t added any expenses today. Log them or set to zero if none."
+		currentValue, err := utils.ParseMoneyValue(response.Values[0][0].(string))
+		if err != nil {
+			return false, err
 		}
+		return currentValue != 0, nil
 	}
 
-	return domain.InvalidMessage
+	return false, nil
 }
 
-func (gss *GoogleSheetsService) updateSheetValuesAndNotes(sheetId int64, inputValue string) error {
-	value, err := strconv.ParseFloat(strings.TrimSpace(strings.Split(inputValue, "/")[0]), 64)
+func (gss *GoogleSheetsService) RecordTransaction(transaction *domain.Transaction) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	gss.mutex.Lock()
+	defer gss.mutex.Unlock()
+
+	date := transaction.Day(gss.now())
+	sheetId, err := gss.getYearSheetId(date)
 	if err != nil {
 		return err
 	}
-	if value == 0 {
-		return nil
+
+	return gss.updateSheetValuesAndNotes(sheetId, date, transaction)
+}
+
+func (gss *GoogleSheetsService) getCurrentYearSheetId() (int64, error) {
+	return gss.getYearSheetId(gss.now())
+}
+
+func (gss *GoogleSheetsService) getYearSheetId(date time.Time) (int64, error) {
+	return gss.client.GetSheetId(gss.appConfig.Google.SheetId, utils.GetSheetName(gss.appConfig.Layout, date))
+}
+
+func (gss *GoogleSheetsService) getCurrentYearValue(rowAndColumnRange string) (float64, error) {
+	_, err := gss.getCurrentYearSheetId()
+	if err != nil {
+		return 0, err
 	}
 
-	isIncome := value > 0
+	return gss.getValue(rowAndColumnRange)
+}
 
-	row := utils.GetCurrentDayRow()
-	column := utils.GetCurrentIncomeColumnNumber()
-	rowAndColumnRange := utils.BuildIncomeRange()
+func (gss *GoogleSheetsService) getValue(rowAndColumnRange string) (float64, error) {
+	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, rowAndColumnRange)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(response.Values) > 0 && len(response.Values[0]) > 0 {
+		return utils.ParseMoneyValue(response.Values[0][0].(string))
+	}
+
+	return 0, nil
+}
+
+func (gss *GoogleSheetsService) updateSheetValuesAndNotes(sheetId int64, date time.Time, transaction *domain.Transaction) error {
+	isIncome := transaction.IsIncome()
+
+	row := utils.GetDayRow(gss.appConfig.Layout, date)
+	column := utils.GetIncomeColumnNumber(gss.appConfig.Layout, date)
+	rowAndColumnRange := utils.BuildIncomeRange(gss.appConfig.Layout, date)
 
 	if !isIncome {
-		row = utils.GetCurrentDayRow()
-		column = utils.GetCurrentDailyOutcomeColumnNumber()
-		rowAndColumnRange = utils.BuildDailyOutcomeRange()
+		row = utils.GetDayRow(gss.appConfig.Layout, date)
+		column = utils.GetDailyOutcomeColumnNumber(gss.appConfig.Layout, date)
+		rowAndColumnRange = utils.BuildDailyOutcomeRange(gss.appConfig.Layout, date)
 	}
 
 	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, rowAndColumnRange)
@@ -188,9 +477,11 @@ func (gss *GoogleSheetsService) updateSheetValuesAndNotes(sheetId int64, inputVa
 		return err
 	}
 
+	previousValue := ""
 	currentValue := "0"
 	if len(response.Values) > 0 && len(response.Values[0]) > 0 {
-		currentValue = utils.CleanMoneyValue(response.Values[0][0].(string))
+		previousValue = fmt.Sprint(response.Values[0][0])
+		currentValue = utils.CleanMoneyValue(previousValue)
 	}
 
 	parsedValue, err := strconv.ParseFloat(currentValue, 64)
@@ -204,17 +495,19 @@ func (gss *GoogleSheetsService) updateSheetValuesAndNotes(sheetId int64, inputVa
 	}
 
 	if existingNote == "" && !isIncome {
+		gss.dailyDefaults[rowAndColumnRange] = previousValue
 		parsedValue = 0
 	}
 
-	newValue := strings.Replace(fmt.Sprintf("%.2f", parsedValue+math.Abs(value)), ".", ",", -1)
+	value := math.Abs(transaction.Amount)
+	newValue := formatCellValue(parsedValue + value)
 
 	err = gss.client.UpdateSheet(gss.appConfig.Google.SheetId, rowAndColumnRange, []interface{}{newValue})
 	if err != nil {
 		return err
 	}
 
-	description := fmt.Sprintf("%.2f - %s", math.Abs(value), strings.Split(inputValue, "/")[1])
+	description := transaction.Note()
 
 	concatenatedNote := description
 	if existingNote != "" {
@@ -227,5 +520,136 @@ func (gss *GoogleSheetsService) updateSheetValuesAndNotes(sheetId int64, inputVa
 		return err
 	}
 
+	gss.lastRecorded = &recordedTransaction{
+		transaction:   transaction,
+		date:          date,
+		sheetId:       sheetId,
+		cellRange:     rowAndColumnRange,
+		row:           row,
+		column:        column,
+		value:         parsedValue + value,
+		note:          concatenatedNote,
+		previousValue: previousValue,
+	}
+
+	if !isIncome {
+		gss.adjustCategoryTotal(date, transaction.Category, value)
+	}
+
 	return nil
 }
+
+func (gss *GoogleSheetsService) UndoLastTransaction() (*domain.Transaction, error) {
+	gss.mutex.Lock()
+	defer gss.mutex.Unlock()
+
+	recorded := gss.lastRecorded
+	if recorded == nil {
+		return nil, domain.ErrNothingToUndo
+	}
+
+	currentValue, err := gss.getValue(recorded.cellRange)
+	if err != nil {
+		return nil, err
+	}
+
+	currentNote, err := gss.client.GetNote(gss.appConfig.Google.SheetId, recorded.sheetId, recorded.cellRange)
+	if err != nil {
+		return nil, err
+	}
+
+	if math.Abs(currentValue-recorded.value) > 0.005 || currentNote != recorded.note {
+		return nil, domain.ErrTransactionEdited
+	}
+
+	transaction := recorded.transaction
+	value := math.Abs(transaction.Amount)
+
+	// the transaction's note is always the last line of the cell
+	remainingNote := ""
+	if i := strings.LastIndex(currentNote, "\n"); i >= 0 {
+		remainingNote = currentNote[:i]
+	}
+
+	// the first outcome of a day replaced the default value, which must come back for the reminder to work
+	newValue := formatCellValue(currentValue - value)
+	if remainingNote == "" {
+		newValue = recorded.previousValue
+	}
+
+	err = gss.client.UpdateSheet(gss.appConfig.Google.SheetId, recorded.cellRange, []interface{}{newValue})
+	if err != nil {
+		return nil, err
+	}
+
+	noteRequest := utils.BuildNoteRequest(remainingNote, recorded.sheetId, recorded.row, recorded.column)
+	if err := gss.client.BatchUpdate(gss.appConfig.Google.SheetId, noteRequest); err != nil {
+		return nil, err
+	}
+
+	gss.lastRecorded = nil
+	if remainingNote == "" {
+		delete(gss.dailyDefaults, recorded.cellRange)
+	}
+
+	if !transaction.IsIncome() {
+		gss.adjustCategoryTotal(recorded.date, transaction.Category, -value)
+	}
+
+	return transaction, nil
+}
+
+// adjustCategoryTotal keeps the category tab in sync, the notes are already written when it runs
+// so a failure here must not make the user log the transaction again
+func (gss *GoogleSheetsService) adjustCategoryTotal(date time.Time, category string, value float64) {
+	if category == "" {
+		return
+	}
+
+	if err := gss.updateCategoryTotal(date, category, value); err != nil {
+		log.Error("failed to update category total: ", err)
+	}
+}
+
+func (gss *GoogleSheetsService) updateCategoryTotal(date time.Time, category string, value float64) error {
+	if gss.appConfig.Layout.CategoryTabPattern == "" {
+		return nil
+	}
+
+	response, err := gss.client.GetValue(gss.appConfig.Google.SheetId, utils.BuildCategoryNamesRange(gss.appConfig.Layout, date))
+	if err != nil {
+		return err
+	}
+
+	row := len(response.Values) + 1
+	found := false
+	for i, values := range response.Values {
+		if len(values) > 0 && strings.EqualFold(fmt.Sprint(values[0]), category) {
+			row = i + 1
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		if row > utils.CategoryRowsLimit {
+			return fmt.Errorf("category tab is full, it supports up to %d categories", utils.CategoryRowsLimit)
+		}
+		categoryNameRange := fmt.Sprintf(utils.RowColumnPattern, utils.GetCategorySheetName(gss.appConfig.Layout, date), "A", row)
+		if err := gss.client.UpdateSheet(gss.appConfig.Google.SheetId, categoryNameRange, []interface{}{category}); err != nil {
+			return err
+		}
+	}
+
+	categoryRange := utils.BuildCategoryRange(gss.appConfig.Layout, date, row)
+	currentValue, err := gss.getValue(categoryRange)
+	if err != nil {
+		return err
+	}
+
+	return gss.client.UpdateSheet(gss.appConfig.Google.SheetId, categoryRange, []interface{}{formatCellValue(currentValue + value)})
+}
+
+func formatCellValue(value float64) string {
+	return strings.Replace(fmt.Sprintf("%.2f", value), ".", ",", -1)
+}
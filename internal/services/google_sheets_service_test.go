@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/client"
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+	"github.com/vitortenor/sheet-bot/internal/fakes"
+	"github.com/vitortenor/sheet-bot/internal/utils"
+)
+
+const testSpreadsheetId = "test-spreadsheet"
+
+var testLayout = configuration.DefaultLayout()
+
+func newTestGoogleSheetsService(t *testing.T) (*GoogleSheetsService, *fakes.GoogleSheetsServer) {
+	t.Helper()
+
+	server := fakes.NewGoogleSheetsServer(t, testSpreadsheetId)
+	server.AddSheet(utils.GetSheetName(testLayout, time.Now()))
+
+	srv, err := server.Service(context.Background())
+	require.NoError(t, err)
+
+	appConfig := &configuration.ApplicationConfig{}
+	appConfig.Google.SheetId = testSpreadsheetId
+	appConfig.Layout = testLayout
+
+	return NewGoogleSheetsService(appConfig, client.NewGoogleSheetsClient(srv)), server
+}
+
+func TestGoogleSheetsService_RecordTransaction(t *testing.T) {
+
+	_ = t.Run("outcome replaces the default daily value and appends notes", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "R$ 50,00")
+
+		// act
+		errFirst := gss.RecordTransaction(&domain.Transaction{Amount: -30.5, Description: "cerveja"})
+		errSecond := gss.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+
+		// assert
+		_ = assert.NoError(t, errFirst)
+		_ = assert.NoError(t, errSecond)
+		_ = assert.Equal(t, "40,50", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "30.50 - cerveja\n10.00 - pao", server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("income adds to the current value", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildIncomeRange(testLayout, time.Now()), "R$ 1.000,00")
+
+		// act
+		err := gss.RecordTransaction(&domain.Transaction{Amount: 200, Description: "vendi um produto"})
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "1200,00", server.Value(utils.BuildIncomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "200.00 - vendi um produto", server.Note(utils.BuildIncomeRange(testLayout, time.Now())))
+		_ = assert.Empty(t, server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("foreign currency outcome keeps the original amount in the note", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		transaction := &domain.Transaction{Amount: -15, Description: "taxi", Currency: "USD"}
+		transaction.Convert(5.4)
+
+		// act
+		err := gss.RecordTransaction(transaction)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "81,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "81.00 - taxi (USD 15.00)", server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("backdated outcome goes to its day and yearly sheet", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		lastYear := time.Now().AddDate(-1, 0, 0)
+		server.AddSheet(utils.GetSheetName(testLayout, lastYear))
+
+		// act
+		err := gss.RecordTransaction(&domain.Transaction{Amount: -20, Description: "padaria", Date: lastYear})
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "20,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, lastYear)))
+		_ = assert.Equal(t, "20.00 - padaria", server.Note(utils.BuildDailyOutcomeRange(testLayout, lastYear)))
+		_ = assert.Empty(t, server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("missing yearly sheet", func(t *testing.T) {
+		// arrange
+		server := fakes.NewGoogleSheetsServer(t, testSpreadsheetId)
+		srv, _ := server.Service(context.Background())
+		appConfig := &configuration.ApplicationConfig{}
+		appConfig.Google.SheetId = testSpreadsheetId
+		appConfig.Layout = testLayout
+		gss := NewGoogleSheetsService(appConfig, client.NewGoogleSheetsClient(srv))
+
+		// act
+		err := gss.RecordTransaction(&domain.Transaction{Amount: -1, Description: "cafe"})
+
+		// assert
+		_ = assert.Error(t, err)
+	})
+}
+
+func TestGoogleSheetsService_UndoLastTransaction(t *testing.T) {
+
+	_ = t.Run("reverts the value and the note", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe"})
+
+		// act
+		undone, err := gss.UndoLastTransaction()
+		_, errAgain := gss.UndoLastTransaction()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "cafe", undone.Description)
+		_ = assert.ErrorIs(t, errAgain, domain.ErrNothingToUndo)
+		_ = assert.Equal(t, "10,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "10.00 - pao", server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("restores the default value when undoing the first outcome of the day", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "R$ 50,00")
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -30, Description: "cerveja"})
+
+		// act
+		_, err := gss.UndoLastTransaction()
+		needsReminder, errReminder := gss.NeedsDailyReminder()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.NoError(t, errReminder)
+		_ = assert.Equal(t, "R$ 50,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Empty(t, server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.True(t, needsReminder)
+	})
+
+	_ = t.Run("refuses when the cell was edited", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: 200, Description: "venda"})
+		_ = server.SetValue(utils.BuildIncomeRange(testLayout, time.Now()), "R$ 250,00")
+
+		// act
+		_, err := gss.UndoLastTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrTransactionEdited)
+		_ = assert.Equal(t, "R$ 250,00", server.Value(utils.BuildIncomeRange(testLayout, time.Now())))
+	})
+}
+
+func TestGoogleSheetsService_DailyNotes(t *testing.T) {
+
+	_ = t.Run("remove and edit recompute the daily value", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		gss.appConfig.Layout.CategoryTabPattern = "{year} categorias"
+		server.AddSheet(utils.GetCategorySheetName(gss.appConfig.Layout, time.Now()))
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe", Category: "lanche"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -25, Description: "uber"})
+
+		// act
+		replaced, errEdit := gss.EditDailyNote(2, &domain.Transaction{Amount: -3, Description: "cafe", Category: "lanche"})
+		removed, errRemove := gss.RemoveDailyNote(1)
+		_, errMissing := gss.RemoveDailyNote(3)
+
+		// assert
+		_ = assert.NoError(t, errEdit)
+		_ = assert.NoError(t, errRemove)
+		_ = assert.Equal(t, "300.00 - cafe #lanche", replaced.Note())
+		_ = assert.Equal(t, "10.00 - pao", removed.Note())
+		_ = assert.ErrorIs(t, errMissing, domain.ErrNoteNotFound)
+		_ = assert.Equal(t, "28,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "3.00 - cafe #lanche\n25.00 - uber", server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Equal(t, "3,00", server.Value(utils.BuildCategoryRange(gss.appConfig.Layout, time.Now(), 1)))
+	})
+
+	_ = t.Run("removing the only note restores the default value", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "R$ 50,00")
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -30, Description: "cerveja"})
+
+		// act
+		_, err := gss.RemoveDailyNote(1)
+		needsReminder, _ := gss.NeedsDailyReminder()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "R$ 50,00", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Empty(t, server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.True(t, needsReminder)
+	})
+
+	_ = t.Run("removing the only note clears an unknown default instead of zeroing the day", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "30,00")
+		_ = server.SetNote(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "30.00 - cerveja")
+
+		// act
+		_, err := gss.RemoveDailyNote(1)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Empty(t, server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+		_ = assert.Empty(t, server.Note(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("undo refuses after a note was edited", func(t *testing.T) {
+		// arrange
+		gss, _ := newTestGoogleSheetsService(t)
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe"})
+		_, _ = gss.EditDailyNote(1, &domain.Transaction{Amount: -3, Description: "cafe"})
+
+		// act
+		_, err := gss.UndoLastTransaction()
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrTransactionEdited)
+	})
+}
+
+func TestGoogleSheetsService_Queries(t *testing.T) {
+
+	_ = t.Run("daily outcome, balance and notes", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildBalanceRange(testLayout, time.Now()), "-R$ 1.234,56")
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -25, Description: "uber"})
+
+		// act
+		dailyOutcome, _ := gss.DailyOutcome()
+		balance, _ := gss.Balance()
+		notes, _ := gss.DailyNotes()
+
+		// assert
+		_ = assert.Equal(t, 25.0, dailyOutcome)
+		_ = assert.Equal(t, -1234.56, balance)
+		_ = assert.Equal(t, []string{"25.00 - uber"}, notes)
+	})
+
+	_ = t.Run("zero daily", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, time.Now()), "R$ 50,00")
+
+		// act
+		needsReminderBefore, _ := gss.NeedsDailyReminder()
+		err := gss.ZeroDaily()
+		needsReminderAfter, _ := gss.NeedsDailyReminder()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.True(t, needsReminderBefore)
+		_ = assert.False(t, needsReminderAfter)
+		_ = assert.Equal(t, "0", server.Value(utils.BuildDailyOutcomeRange(testLayout, time.Now())))
+	})
+
+	_ = t.Run("zero daily with notes", func(t *testing.T) {
+		// arrange
+		gss, _ := newTestGoogleSheetsService(t)
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -25, Description: "uber"})
+
+		// act
+		err := gss.ZeroDaily()
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrDailyHasNotes)
+	})
+}
+
+func TestGoogleSheetsService_MonthSummary(t *testing.T) {
+
+	_ = t.Run("reads the elapsed days of the month", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		today := time.Date(2025, 3, 3, 10, 0, 0, 0, time.Local)
+		gss.now = func() time.Time { return today }
+		server.AddSheet(utils.GetSheetName(testLayout, today))
+		_ = server.SetValue(utils.BuildIncomeRange(testLayout, today.AddDate(0, 0, -2)), "R$ 2.000,00")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, today.AddDate(0, 0, -2)), "R$ 50,00")
+		_ = server.SetValue(utils.BuildBalanceRange(testLayout, today.AddDate(0, 0, -2)), "R$ 1.950,00")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, today.AddDate(0, 0, -1)), "R$ 80,50")
+		_ = server.SetValue(utils.BuildBalanceRange(testLayout, today.AddDate(0, 0, -1)), "R$ 1.869,50")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, today.AddDate(0, 0, 1)), "R$ 999,00")
+
+		// act
+		summary, err := gss.MonthSummary(time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 2000.0, summary.Income)
+		_ = assert.Equal(t, 130.5, summary.Outcome)
+		_ = assert.Equal(t, 1869.5, summary.ClosingBalance)
+		_ = assert.Equal(t, []float64{50, 80.5, 0}, summary.DailyOutcomes)
+	})
+
+	_ = t.Run("missing yearly sheet", func(t *testing.T) {
+		// arrange
+		gss, _ := newTestGoogleSheetsService(t)
+
+		// act
+		_, err := gss.MonthSummary(time.Date(1999, 3, 1, 0, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Error(t, err)
+	})
+}
+
+func TestGoogleSheetsService_Transactions(t *testing.T) {
+
+	_ = t.Run("parses the notes of every day in the period", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		day := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)
+		nextDay := day.AddDate(0, 0, 1)
+		server.AddSheet(utils.GetSheetName(testLayout, day))
+		_ = server.SetValue(utils.BuildIncomeRange(testLayout, day), "R$ 1.000,00")
+		_ = server.SetNote(utils.BuildIncomeRange(testLayout, day), "1000.00 - salario")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, day), "R$ 40,50")
+		_ = server.SetNote(utils.BuildDailyOutcomeRange(testLayout, day), "30.50 - cerveja #lazer (@Ana)\n10.00 - pao")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, nextDay), "R$ 12,00")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, nextDay.AddDate(0, 0, 1)), "R$ 99,00")
+
+		// act
+		transactions, err := gss.Transactions(day, nextDay)
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, []*domain.Transaction{
+			{Amount: 1000, Description: "salario", Date: day},
+			{Amount: -30.5, Description: "cerveja", Category: "lazer", Author: "Ana", Date: day},
+			{Amount: -10, Description: "pao", Date: day},
+		}, transactions)
+	})
+
+	_ = t.Run("keeps hand typed incomes and stops at today", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		today := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+		gss.now = func() time.Time { return today }
+		server.AddSheet(utils.GetSheetName(testLayout, today))
+		_ = server.SetValue(utils.BuildIncomeRange(testLayout, today), "R$ 300,00")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, today), "R$ 50,00")
+		_ = server.SetValue(utils.BuildDailyOutcomeRange(testLayout, today.AddDate(0, 0, 1)), "R$ 20,00")
+		_ = server.SetNote(utils.BuildDailyOutcomeRange(testLayout, today.AddDate(0, 0, 1)), "20.00 - padaria")
+
+		// act
+		transactions, err := gss.Transactions(time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 12, 31, 0, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, []*domain.Transaction{
+			{Amount: 300, Date: time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)},
+		}, transactions)
+	})
+
+	_ = t.Run("missing yearly sheet", func(t *testing.T) {
+		// arrange
+		gss, _ := newTestGoogleSheetsService(t)
+
+		// act
+		_, err := gss.Transactions(time.Date(1999, 1, 1, 0, 0, 0, 0, time.Local), time.Date(1999, 2, 1, 0, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Error(t, err)
+	})
+}
+
+func TestGoogleSheetsService_Categories(t *testing.T) {
+
+	_ = t.Run("updates the category tab and totals the month notes", func(t *testing.T) {
+		// arrange
+		gss, server := newTestGoogleSheetsService(t)
+		gss.appConfig.Layout.CategoryTabPattern = "{year} categorias"
+		server.AddSheet(utils.GetCategorySheetName(gss.appConfig.Layout, time.Now()))
+		_ = server.SetValue(utils.GetCategorySheetName(gss.appConfig.Layout, time.Now())+"!A1", "transporte")
+
+		// act
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -45, Description: "mercado", Category: "alimentacao"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -25, Description: "uber", Category: "transporte"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -5, Description: "feira", Category: "alimentacao"})
+		_ = gss.RecordTransaction(&domain.Transaction{Amount: -3, Description: "cafe"})
+		totals, err := gss.CategoryTotals()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, map[string]float64{"alimentacao": 50, "transporte": 25, "": 3}, totals)
+		_ = assert.Equal(t, "alimentacao", server.Value(utils.GetCategorySheetName(gss.appConfig.Layout, time.Now())+"!A2"))
+		_ = assert.Equal(t, "50,00", server.Value(utils.BuildCategoryRange(gss.appConfig.Layout, time.Now(), 2)))
+		_ = assert.Equal(t, "25,00", server.Value(utils.BuildCategoryRange(gss.appConfig.Layout, time.Now(), 1)))
+	})
+}
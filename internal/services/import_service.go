@@ -0,0 +1,256 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+	"github.com/vitortenor/sheet-bot/internal/utils"
+)
+
+var (
+	ErrInvalidStatement = errors.New("invalid statement")
+
+	ofxTransactionRegex = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	// OFX 1.x files are SGML and leave elements unclosed, e.g. "<TRNAMT>-45.90"
+	ofxFieldRegex = regexp.MustCompile(`(?i)<(DTPOSTED|TRNAMT|MEMO|NAME)>\s*([^<\r\n]*)`)
+	// '#' would become a category and a trailing parenthesis an author once the note is read back
+	statementDescriptionReplacer = strings.NewReplacer("#", "", "(", "[", ")", "]")
+)
+
+type ImportService struct {
+	ledger Ledger
+}
+
+func NewImportService(ledger Ledger) *ImportService {
+	return &ImportService{
+		ledger: ledger,
+	}
+}
+
+// ParseCSV reads a bank statement whose columns are mapped by the profile
+func (is *ImportService) ParseCSV(r io.Reader, profile configuration.CsvProfile) ([]*domain.Transaction, error) {
+	reader := csv.NewReader(r)
+	if profile.Delimiter != "" {
+		reader.Comma = []rune(profile.Delimiter)[0]
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	indexes := make([]int, 0, 3)
+	for _, name := range []string{profile.DateColumn, profile.DescriptionColumn, profile.AmountColumn} {
+		index, ok := columns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q not found", ErrInvalidStatement, name)
+		}
+		indexes = append(indexes, index)
+	}
+
+	var transactions []*domain.Transaction
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		if len(record) <= max(indexes[0], indexes[1], indexes[2]) {
+			continue
+		}
+
+		date, err := time.ParseInLocation(profile.DateLayout, strings.TrimSpace(record[indexes[0]]), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatement, line, err)
+		}
+		amount, err := parseStatementAmount(record[indexes[2]], profile.DecimalComma)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatement, line, err)
+		}
+		if profile.OutcomePositive {
+			amount = -amount
+		}
+
+		transactions = append(transactions, newStatementTransaction(date, amount, record[indexes[1]]))
+	}
+
+	return transactions, nil
+}
+
+// ParseOFX reads the transactions of an OFX statement, both the SGML and the XML flavors
+func (is *ImportService) ParseOFX(r io.Reader) ([]*domain.Transaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*domain.Transaction
+	for _, block := range ofxTransactionRegex.FindAllStringSubmatch(string(data), -1) {
+		fields := make(map[string]string)
+		for _, field := range ofxFieldRegex.FindAllStringSubmatch(block[1], -1) {
+			fields[strings.ToUpper(field[1])] = strings.TrimSpace(field[2])
+		}
+
+		// dates may carry a time and a timezone, e.g. "20260312000000[-3:BRT]"
+		posted := fields["DTPOSTED"]
+		if len(posted) < 8 {
+			return nil, fmt.Errorf("%w: transaction without date", ErrInvalidStatement)
+		}
+		date, err := time.ParseInLocation("20060102", posted[:8], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(fields["TRNAMT"], ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+
+		description := fields["MEMO"]
+		if description == "" {
+			description = fields["NAME"]
+		}
+		transactions = append(transactions, newStatementTransaction(date, amount, description))
+	}
+
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("%w: no transactions found", ErrInvalidStatement)
+	}
+	return transactions, nil
+}
+
+// Import records the transactions that are not in their day's notes yet and returns how many were recorded and skipped,
+// a day holding the same value and description twice skips two lines
+func (is *ImportService) Import(transactions []*domain.Transaction) (int, int, error) {
+	if len(transactions) == 0 {
+		return 0, 0, nil
+	}
+
+	from, to := statementPeriod(transactions)
+	existing, err := is.ledger.Transactions(from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	recorded := make(map[string]int)
+	for _, transaction := range existing {
+		recorded[statementKey(transaction)]++
+	}
+
+	imported, skipped := 0, 0
+	for _, transaction := range transactions {
+		key := statementKey(transaction)
+		if recorded[key] > 0 {
+			recorded[key]--
+			skipped++
+			continue
+		}
+		if transaction.Validate() != nil {
+			skipped++
+			continue
+		}
+
+		if err := is.ledger.RecordTransaction(transaction); err != nil {
+			return imported, skipped, fmt.Errorf("failed to record %s: %w", transaction.Note(), err)
+		}
+		imported++
+	}
+
+	return imported, skipped, nil
+}
+
+// Reconcile compares the statement with the transactions logged between its first and last days,
+// the days around the period are only searched for matches
+func (is *ImportService) Reconcile(statement []*domain.Transaction) (*domain.Reconciliation, error) {
+	if len(statement) == 0 {
+		return &domain.Reconciliation{}, nil
+	}
+
+	from, to := statementPeriod(statement)
+	logged, err := is.ledger.Transactions(from.AddDate(0, 0, -domain.ReconcileDayTolerance), to.AddDate(0, 0, domain.ReconcileDayTolerance))
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := domain.Reconcile(statement, logged)
+	unmatched := reconciliation.Unmatched[:0]
+	for _, transaction := range reconciliation.Unmatched {
+		if !transaction.Date.Before(from) && !transaction.Date.After(to) {
+			unmatched = append(unmatched, transaction)
+		}
+	}
+	reconciliation.Unmatched = unmatched
+
+	return reconciliation, nil
+}
+
+// ReconciliationReport lists the matched lines, the ones missing from the sheet and the ones missing from the statement
+func ReconciliationReport(reconciliation *domain.Reconciliation) string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "matched (%d):\n", len(reconciliation.Matched))
+	for _, pair := range reconciliation.Matched {
+		fmt.Fprintf(&report, "  %s <- %s\n", formatReconciledLine(pair.Logged), pair.Statement.Description)
+	}
+	fmt.Fprintf(&report, "in the statement but not logged (%d):\n", len(reconciliation.Unlogged))
+	for _, transaction := range reconciliation.Unlogged {
+		fmt.Fprintf(&report, "  %s\n", formatReconciledLine(transaction))
+	}
+	fmt.Fprintf(&report, "logged but not in the statement (%d):\n", len(reconciliation.Unmatched))
+	for _, transaction := range reconciliation.Unmatched {
+		fmt.Fprintf(&report, "  %s\n", formatReconciledLine(transaction))
+	}
+	return report.String()
+}
+
+func formatReconciledLine(transaction *domain.Transaction) string {
+	return fmt.Sprintf("%s %s %s", transaction.Date.Format("02/01"), utils.FormatMoney(transaction.Amount), transaction.Description)
+}
+
+func statementPeriod(statement []*domain.Transaction) (time.Time, time.Time) {
+	from, to := statement[0].Date, statement[0].Date
+	for _, transaction := range statement {
+		if transaction.Date.Before(from) {
+			from = transaction.Date
+		}
+		if transaction.Date.After(to) {
+			to = transaction.Date
+		}
+	}
+	return from, to
+}
+
+func newStatementTransaction(date time.Time, amount float64, description string) *domain.Transaction {
+	return &domain.Transaction{
+		Amount:      amount,
+		Description: strings.Join(strings.Fields(statementDescriptionReplacer.Replace(description)), " "),
+		Date:        date,
+	}
+}
+
+// statementKey identifies a transaction by its day, value and description, as written in the notes
+func statementKey(transaction *domain.Transaction) string {
+	return fmt.Sprintf("%s|%.2f|%s", transaction.Date.Format(dayLayout), transaction.Amount, strings.ToLower(transaction.Description))
+}
+
+func parseStatementAmount(value string, decimalComma bool) (float64, error) {
+	value = strings.Join(strings.Fields(strings.ReplaceAll(value, "R$", "")), "")
+	if decimalComma {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
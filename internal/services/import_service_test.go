@@ -0,0 +1,148 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func TestImportService_Parse(t *testing.T) {
+	day := time.Date(2026, 3, 12, 0, 0, 0, 0, time.Local)
+
+	_ = t.Run("nubank credit card csv", func(t *testing.T) {
+		// arrange
+		statement := "date,title,amount\n2026-03-12,Mercado #1 (Centro),45.90\n2026-03-12,Estorno,-10.00\n"
+
+		// act
+		transactions, err := NewImportService(nil).ParseCSV(strings.NewReader(statement), configuration.DefaultCsvProfiles()["nubank"])
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, []*domain.Transaction{
+			{Amount: -45.9, Description: "Mercado 1 [Centro]", Date: day},
+			{Amount: 10, Description: "Estorno", Date: day},
+		}, transactions)
+	})
+
+	_ = t.Run("itau checking account csv", func(t *testing.T) {
+		// arrange
+		statement := "data;lancamento;valor\n12/03/2026;PIX ENVIADO  ANA;-1.234,56\n"
+
+		// act
+		transactions, err := NewImportService(nil).ParseCSV(strings.NewReader(statement), configuration.DefaultCsvProfiles()["itau"])
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, []*domain.Transaction{{Amount: -1234.56, Description: "PIX ENVIADO ANA", Date: day}}, transactions)
+	})
+
+	_ = t.Run("csv without the mapped column", func(t *testing.T) {
+		// arrange
+		statement := "data,descricao,valor\n"
+
+		// act
+		_, err := NewImportService(nil).ParseCSV(strings.NewReader(statement), configuration.DefaultCsvProfiles()["nubank"])
+
+		// assert
+		_ = assert.ErrorIs(t, err, ErrInvalidStatement)
+	})
+
+	_ = t.Run("sgml ofx", func(t *testing.T) {
+		// arrange
+		statement := "OFXHEADER:100\nDATA:OFXSGML\n<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>\n" +
+			"<STMTTRN>\n<TRNTYPE>DEBIT\n<DTPOSTED>20260312000000[-3:BRT]\n<TRNAMT>-25.00\n<NAME>UBER\n<MEMO>Uber *Trip\n</STMTTRN>\n" +
+			"<STMTTRN>\n<TRNTYPE>CREDIT\n<DTPOSTED>20260312\n<TRNAMT>1500,00\n<NAME>SALARIO\n</STMTTRN>\n" +
+			"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"
+
+		// act
+		transactions, err := NewImportService(nil).ParseOFX(strings.NewReader(statement))
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, []*domain.Transaction{
+			{Amount: -25, Description: "Uber *Trip", Date: day},
+			{Amount: 1500, Description: "SALARIO", Date: day},
+		}, transactions)
+	})
+}
+
+func TestImportService_Import(t *testing.T) {
+	today := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("skips lines already in the day's notes", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		yesterday := time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -45.9, Description: "mercado", Date: yesterday})
+		transactions := []*domain.Transaction{
+			{Amount: -45.9, Description: "Mercado", Date: yesterday},
+			{Amount: -45.9, Description: "Mercado", Date: yesterday},
+			{Amount: -12, Description: "padaria", Date: yesterday},
+			{Amount: 0, Description: "tarifa isenta", Date: yesterday},
+		}
+
+		// act
+		imported, skipped, err := NewImportService(ledger).Import(transactions)
+		recorded, _ := ledger.Transactions(yesterday, yesterday)
+
+		// assert
+		require.NoError(t, err)
+		_ = assert.Equal(t, 2, imported)
+		_ = assert.Equal(t, 2, skipped)
+		_ = assert.Len(t, recorded, 3)
+	})
+}
+
+func TestImportService_Reconcile(t *testing.T) {
+	today := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("lists matched and missing lines", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		day := time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -45.9, Description: "mercado", Date: day})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -12, Description: "feira", Date: day})
+		statement := []*domain.Transaction{
+			{Amount: -45.9, Description: "MERCADO EXTRA", Date: day},
+			{Amount: -25, Description: "UBER *TRIP", Date: day},
+		}
+
+		// act
+		reconciliation, err := NewImportService(ledger).Reconcile(statement)
+
+		// assert
+		require.NoError(t, err)
+		_ = assert.Equal(t, "matched (1):\n"+
+			"  11/03 -R$ 45,90 mercado <- MERCADO EXTRA\n"+
+			"in the statement but not logged (1):\n"+
+			"  11/03 -R$ 25,00 UBER *TRIP\n"+
+			"logged but not in the statement (1):\n"+
+			"  11/03 -R$ 12,00 feira\n", ReconciliationReport(reconciliation))
+	})
+
+	_ = t.Run("matches a purchase logged the day before the statement", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		day := time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -45.9, Description: "mercado", Date: day.AddDate(0, 0, -1)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -8, Description: "cafe", Date: day.AddDate(0, 0, -1)})
+		statement := []*domain.Transaction{
+			{Amount: -45.9, Description: "MERCADO EXTRA", Date: day},
+		}
+
+		// act
+		reconciliation, err := NewImportService(ledger).Reconcile(statement)
+
+		// assert
+		require.NoError(t, err)
+		_ = assert.Len(t, reconciliation.Matched, 1)
+		_ = assert.Empty(t, reconciliation.Unlogged)
+		_ = assert.Empty(t, reconciliation.Unmatched)
+	})
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"time"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+// Ledger is the system of record the bot reads balances from and writes transactions to.
+type Ledger interface {
+	RecordTransaction(transaction *domain.Transaction) error
+	// UndoLastTransaction reverts the last transaction recorded by this ledger and returns it, failing with
+	// domain.ErrNothingToUndo when there is none and domain.ErrTransactionEdited when its cell changed since
+	UndoLastTransaction() (*domain.Transaction, error)
+	DailyOutcome() (float64, error)
+	Balance() (float64, error)
+	DailyNotes() ([]string, error)
+	// RemoveDailyNote removes the note at the one-based index of DailyNotes and recomputes the day's outcome,
+	// failing with domain.ErrNoteNotFound when there is no such note
+	RemoveDailyNote(index int) (*domain.Transaction, error)
+	// EditDailyNote replaces the note at the one-based index of DailyNotes with the outcome and returns the old one
+	EditDailyNote(index int, transaction *domain.Transaction) (*domain.Transaction, error)
+	// CategoryTotals returns the current month outcome per category, uncategorized outcome is keyed by ""
+	CategoryTotals() (map[string]float64, error)
+	// MonthSummary returns the totals of the month starting at the given day, up to today for the current month
+	MonthSummary(month time.Time) (*domain.MonthSummary, error)
+	// Transactions returns the transactions recorded between from and to, both days included, oldest first
+	Transactions(from, to time.Time) ([]*domain.Transaction, error)
+	// ZeroDaily sets the day's outcome as zero, failing with domain.ErrDailyHasNotes if anything was logged
+	ZeroDaily() error
+	// NeedsDailyReminder reports whether nothing was logged or zeroed today
+	NeedsDailyReminder() (bool, error)
+}
@@ -2,29 +2,54 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/labstack/gommon/log"
 
 	"github.com/vitortenor/sheet-bot/internal/configuration"
 	"github.com/vitortenor/sheet-bot/internal/domain"
+	"github.com/vitortenor/sheet-bot/internal/utils"
+)
+
+const (
+	ZeroBalance = domain.ZeroBalanceMessage
+	SystemError = domain.SystemErrorMessage
+
+	uncategorized    = "sem categoria"
+	budgetAlertRatio = 0.8
 )
 
 type MessageService struct {
 	context            context.Context
 	appConfig          *configuration.ApplicationConfig
-	sheetService       *GoogleSheetsService
+	ledger             Ledger
 	aiService          *OllamaAIService
 	interpreterService *MessageInterpreterService
+	budgetService      *BudgetService
+	recurringService   *RecurringService
+	exchangeRates      *ExchangeRateService
+	exportService      *ExportService
 }
 
-func NewMessageService(ctx context.Context, appConfig *configuration.ApplicationConfig, gss *GoogleSheetsService,
-	oas *OllamaAIService, mis *MessageInterpreterService) *MessageService {
+func NewMessageService(ctx context.Context, appConfig *configuration.ApplicationConfig, ledger Ledger,
+	oas *OllamaAIService, mis *MessageInterpreterService, bs *BudgetService, rs *RecurringService, ers *ExchangeRateService) *MessageService {
 	return &MessageService{
 		context:            ctx,
-		sheetService:       gss,
+		ledger:             ledger,
 		aiService:          oas,
 		appConfig:          appConfig,
 		interpreterService: mis,
+		budgetService:      bs,
+		recurringService:   rs,
+		exchangeRates:      ers,
+		exportService:      NewExportService(ledger),
 	}
 }
 
@@ -35,21 +60,40 @@ func (ms *MessageService) ProcessAndReply(message *domain.Message) *domain.Messa
 		return nil
 	}
 
+	// well formed transactions skip the AI so nothing, like a category hashtag, gets rephrased
+	if message.IsIncomeOrOutcome() {
+		return ms.processIncomeOutcome(message)
+	}
+
+	// commands are matched before the AI so their arguments are never read as a transaction
+	command := &domain.Message{
+		Sender:  message.Sender,
+		Message: message.Message,
+	}
+	command.Normalize()
+	if reply := ms.processCommand(command); reply != nil {
+		return reply
+	}
+
 	if ms.appConfig.Ai.IsEnabled {
-		if resp := ms.aiService.GetOllamaAIResponse(message.Message); resp != "false" {
+		transaction, err := ms.aiService.ExtractTransaction(ms.context, message.Message)
+		if err == nil {
 			return ms.processIncomeOutcome(&domain.Message{
-				Message: resp,
+				Sender:    message.Sender,
+				Timestamp: message.Timestamp,
+				Message:   transaction.Message(),
 			})
 		}
-	}
-
-	if message.IsIncomeOrOutcome() {
-		return ms.processIncomeOutcome(message)
+		// the interpreter below is the fallback while the ai is unavailable
+		if !errors.Is(err, domain.ErrNotATransaction) && !errors.Is(err, ErrAiUnavailable) {
+			log.Warn("ai answer discarded: ", err)
+		}
 	}
 
 	if resp := ms.interpreterService.InterpretMessage(message.Message); resp != false {
 		if msg, ok := resp.(string); ok {
 			interpreted := &domain.Message{
+				Sender:  message.Sender,
 				Message: msg,
 			}
 			if interpreted.IsIncomeOrOutcome() {
@@ -58,31 +102,86 @@ func (ms *MessageService) ProcessAndReply(message *domain.Message) *domain.Messa
 		}
 	}
 
-	message.Normalize()
+	return ms.newReply(domain.InvalidMessage + ": " + command.Message)
+}
 
+// processCommand replies to a normalized command message, or returns nil when it is not a command
+func (ms *MessageService) processCommand(message *domain.Message) *domain.Message {
 	switch {
 	case message.IsDailyExpense():
 		log.Info("processing daily expenses message")
-		return ms.newReply(ms.sheetService.GetDailyExpenses())
+		return ms.newReply(ms.getDailyExpenses())
 
 	case message.IsDailyBalance():
 		log.Info("processing daily balance message")
-		return ms.newReply(ms.sheetService.GetBalance())
+		return ms.newReply(ms.getBalance())
 
 	case message.IsDetailedDailyBalance():
 		log.Info("processing detailed daily balance message")
-		return ms.newReply(ms.sheetService.GetDetailedDailyBalance())
+		return ms.newReply(ms.getDetailedDailyBalance())
+
+	case message.IsCategories():
+		log.Info("processing categories message")
+		return ms.newReply(ms.getCategoryTotals())
+
+	case message.IsReport():
+		log.Info("processing report message")
+		return ms.newReply(ms.getMonthReport(message))
+
+	case message.IsChart():
+		log.Info("processing chart message")
+		return ms.getChart(message)
+
+	case message.IsExport():
+		log.Info("processing export message")
+		return ms.getExport(message)
+
+	case message.IsBudgets():
+		log.Info("processing budgets message")
+		return ms.newReply(ms.getBudgets())
+
+	case message.IsSetBudget():
+		log.Info("processing set budget message")
+		return ms.newReply(ms.setBudget(message))
+
+	case message.IsSetExchangeRate():
+		log.Info("processing set exchange rate message")
+		return ms.newReply(ms.setExchangeRate(message))
+
+	case message.IsAddRecurring():
+		log.Info("processing add recurring message")
+		return ms.newReply(ms.addRecurring(message))
+
+	case message.IsRecurringList():
+		log.Info("processing recurring list message")
+		return ms.newReply(ms.getRecurringList())
+
+	case message.IsRemoveRecurring():
+		log.Info("processing remove recurring message")
+		return ms.newReply(ms.removeRecurring(message))
+
+	case message.IsRemoveNote():
+		log.Info("processing remove note message")
+		return ms.newReply(ms.removeDailyNote(message))
+
+	case message.IsEditNote():
+		log.Info("processing edit note message")
+		return ms.newReply(ms.editDailyNote(message))
+
+	case message.IsUndo():
+		log.Info("processing undo message")
+		return ms.newReply(ms.undoLastTransaction())
 
 	case message.IsSetAsZero():
 		log.Info("processing set as zero message")
-		return ms.newReply(ms.sheetService.SetDailyAsZero())
+		return ms.newReply(ms.setDailyAsZero())
 
 	case message.IsReminderVerification():
 		log.Info("processing reminder verification message")
-		return ms.newReply(ms.sheetService.GetDailyReminder())
+		return ms.newReply(ms.GetDailyReminder())
 
 	default:
-		return ms.newReply(domain.InvalidMessage + ": " + message.Message)
+		return nil
 	}
 }
 
@@ -90,7 +189,577 @@ func (ms *MessageService) processIncomeOutcome(msg *domain.Message) *domain.Mess
 	log.Info("processing income/outcome message")
 	msg.Normalize()
 
-	return ms.newReply(ms.sheetService.ProcessAndUpdateSheet(msg.Message))
+	return ms.newReply(ms.recordTransaction(msg))
+}
+
+func (ms *MessageService) getDailyExpenses() string {
+	dailyOutcome, err := ms.ledger.DailyOutcome()
+	if err != nil {
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + utils.FormatMoney(dailyOutcome)
+}
+
+func (ms *MessageService) getBalance() string {
+	balance, err := ms.ledger.Balance()
+	if err != nil {
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + utils.FormatMoney(balance)
+}
+
+func (ms *MessageService) setDailyAsZero() string {
+	err := ms.ledger.ZeroDaily()
+	if errors.Is(err, domain.ErrDailyHasNotes) {
+		return domain.SystemMessagePrefix + "daily value has notes, please remove them before setting as zero"
+	}
+	if err != nil {
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "daily value set as zero"
+}
+
+func (ms *MessageService) recordTransaction(message *domain.Message) string {
+	transaction, err := message.ToTransaction()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	if err := ms.exchangeRates.Convert(transaction); err != nil {
+		return ms.missingExchangeRate(transaction)
+	}
+
+	err = ms.ledger.RecordTransaction(transaction)
+	if err != nil {
+		return SystemError
+	}
+
+	reply := domain.SystemMessagePrefix + "processed " + message.Message
+	if transaction.Currency != "" {
+		reply += " (" + utils.FormatMoney(transaction.Amount) + ")"
+	}
+	if warning := ms.budgetWarning(transaction); warning != "" {
+		reply += "\n" + warning
+	}
+
+	return reply
+}
+
+func (ms *MessageService) undoLastTransaction() string {
+	transaction, err := ms.ledger.UndoLastTransaction()
+	switch {
+	case errors.Is(err, domain.ErrNothingToUndo):
+		return domain.SystemMessagePrefix + "nothing to undo"
+	case errors.Is(err, domain.ErrTransactionEdited):
+		return domain.SystemMessagePrefix + "the last transaction was edited in the sheet, please fix it there"
+	case err != nil:
+		log.Error("failed to undo transaction: ", err)
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "undone " + transaction.String()
+}
+
+func (ms *MessageService) getDetailedDailyBalance() string {
+	notes, err := ms.ledger.DailyNotes()
+	if err != nil {
+		return SystemError
+	}
+
+	if len(notes) == 0 {
+		return domain.SystemMessagePrefix + "\n"
+	}
+
+	var formattedNotes []string
+	for i, note := range notes {
+		formattedNotes = append(formattedNotes, fmt.Sprintf("%s%d. %s", domain.SystemMessagePrefix, i+1, note))
+	}
+
+	return strings.Join(formattedNotes, "\n")
+}
+
+func (ms *MessageService) removeDailyNote(message *domain.Message) string {
+	index, err := message.ToNoteIndex()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	removed, err := ms.ledger.RemoveDailyNote(index)
+	if errors.Is(err, domain.ErrNoteNotFound) {
+		return domain.SystemMessagePrefix + fmt.Sprintf("note %d not found", index)
+	}
+	if err != nil {
+		log.Error("failed to remove note: ", err)
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "removed " + removed.Note()
+}
+
+func (ms *MessageService) editDailyNote(message *domain.Message) string {
+	index, transaction, err := message.ToNoteEdit()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	if err := ms.exchangeRates.Convert(transaction); err != nil {
+		return ms.missingExchangeRate(transaction)
+	}
+
+	replaced, err := ms.ledger.EditDailyNote(index, transaction)
+	if errors.Is(err, domain.ErrNoteNotFound) {
+		return domain.SystemMessagePrefix + fmt.Sprintf("note %d not found", index)
+	}
+	if err != nil {
+		log.Error("failed to edit note: ", err)
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "replaced " + replaced.Note() + " with " + transaction.Note()
+}
+
+func (ms *MessageService) getCategoryTotals() string {
+	totals, err := ms.ledger.CategoryTotals()
+	if err != nil {
+		return SystemError
+	}
+
+	if len(totals) == 0 {
+		return domain.SystemMessagePrefix + "no expenses this month"
+	}
+
+	categories := make([]string, 0, len(totals))
+	for category := range totals {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if totals[categories[i]] == totals[categories[j]] {
+			return categories[i] < categories[j]
+		}
+		return totals[categories[i]] > totals[categories[j]]
+	})
+
+	var lines []string
+	for _, category := range categories {
+		name := category
+		if name == "" {
+			name = uncategorized
+		}
+		lines = append(lines, domain.SystemMessagePrefix+name+": "+utils.FormatMoney(totals[category]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (ms *MessageService) getMonthReport(message *domain.Message) string {
+	now := time.Now()
+	month, err := message.ToReportMonth(now)
+	if errors.Is(err, domain.ErrFutureMonth) {
+		return domain.SystemMessagePrefix + "that month has not started yet"
+	}
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	return ms.monthReport("report", month, now)
+}
+
+// getChart renders the month's daily outcome, with the category split for the current month, into the charts directory
+func (ms *MessageService) getChart(message *domain.Message) *domain.Message {
+	now := time.Now()
+	month, err := message.ToChartMonth(now)
+	if errors.Is(err, domain.ErrFutureMonth) {
+		return ms.newReply(domain.SystemMessagePrefix + "that month has not started yet")
+	}
+	if err != nil {
+		return ms.newReply(domain.InvalidMessage)
+	}
+
+	summary, err := ms.ledger.MonthSummary(month)
+	if err != nil {
+		log.Error("failed to read month for the chart: ", err)
+		return ms.newReply(SystemError)
+	}
+
+	// category totals are only kept for the current month
+	var slices []utils.ChartSlice
+	if domain.IsCurrentMonth(month, now) {
+		totals, err := ms.ledger.CategoryTotals()
+		if err != nil {
+			log.Warn("failed to read categories for the chart: ", err)
+		}
+		for category, total := range totals {
+			if category == "" {
+				category = uncategorized
+			}
+			slices = append(slices, utils.ChartSlice{Label: category, Value: total})
+		}
+		sort.Slice(slices, func(i, j int) bool { return slices[i].Label < slices[j].Label })
+	}
+
+	chart, err := utils.RenderSpendingChart("gastos diarios "+month.Format("01/2006"), summary.DailyOutcomes, slices)
+	if err != nil {
+		log.Error("failed to render chart: ", err)
+		return ms.newReply(SystemError)
+	}
+
+	path, err := saveFile(ms.appConfig.Charts.Dir, "grafico-"+month.Format("2006-01")+".png", chart)
+	if err != nil {
+		log.Error("failed to save chart: ", err)
+		return ms.newReply(SystemError)
+	}
+
+	return &domain.Message{
+		Message:    fmt.Sprintf("%schart %s, outcome %s", domain.SystemMessagePrefix, month.Format("01/2006"), utils.FormatMoney(summary.Outcome)),
+		Attachment: path,
+	}
+}
+
+// getExport writes the transactions of the period to a csv file in the exports directory
+func (ms *MessageService) getExport(message *domain.Message) *domain.Message {
+	from, to, err := message.ToExportPeriod(time.Now())
+	if err != nil {
+		return ms.newReply(domain.InvalidMessage)
+	}
+
+	var csv strings.Builder
+	count, err := ms.exportService.WriteCSV(&csv, from, to)
+	if err != nil {
+		log.Error("failed to export transactions: ", err)
+		return ms.newReply(SystemError)
+	}
+
+	name := fmt.Sprintf("transacoes-%s-%s.csv", from.Format("20060102"), to.Format("20060102"))
+	path, err := saveFile(ms.appConfig.Exports.Dir, name, []byte(csv.String()))
+	if err != nil {
+		log.Error("failed to save export: ", err)
+		return ms.newReply(SystemError)
+	}
+
+	return &domain.Message{
+		Message: fmt.Sprintf("%sexported %d transactions from %s to %s", domain.SystemMessagePrefix, count,
+			from.Format("02/01/2006"), to.Format("02/01/2006")),
+		Attachment: path,
+	}
+}
+
+// saveFile writes a file to be attached to the chat and returns its path, the directory defaults to the temporary one
+func saveFile(dir, name string, data []byte) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	return path, utils.WriteFileAtomically(path, data)
+}
+
+// GetMonthlyDigest reports the month before now, it is empty when the month cannot be read
+func (ms *MessageService) GetMonthlyDigest(now time.Time) string {
+	previousMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+	report := ms.monthReport("monthly digest", previousMonth, now)
+	if report == SystemError {
+		return ""
+	}
+	return report
+}
+
+func (ms *MessageService) monthReport(title string, month, now time.Time) string {
+	summary, err := ms.ledger.MonthSummary(month)
+	if err != nil {
+		log.Error("failed to build month report: ", err)
+		return SystemError
+	}
+
+	lines := []string{
+		title + " " + month.Format("01/2006"),
+		"income: " + utils.FormatMoney(summary.Income),
+		"outcome: " + utils.FormatMoney(summary.Outcome),
+		"closing balance: " + utils.FormatMoney(summary.ClosingBalance),
+	}
+	if day, outcome := summary.BusiestDay(); day > 0 {
+		lines = append(lines, fmt.Sprintf("busiest day: %02d/%02d (%s)", day, int(month.Month()), utils.FormatMoney(outcome)))
+	}
+	lines = append(lines, "average daily spend: "+utils.FormatMoney(summary.AverageDailySpend()))
+
+	// the previous month may live in a yearly tab that does not exist, the report is still useful without it
+	previousMonth := month.AddDate(0, -1, 0)
+	if previous, err := ms.ledger.MonthSummary(previousMonth); err != nil {
+		log.Warn("failed to compare with the previous month: ", err)
+	} else {
+		label, previousOutcome := previousMonth.Format("01/2006"), previous.Outcome
+		// a month still going on is compared with the same days of the previous one
+		if days := domain.ElapsedDays(month, now); days < domain.DaysInMonth(month) {
+			label += fmt.Sprintf(" (first %d days)", days)
+			previousOutcome = previous.OutcomeUntil(days)
+		}
+		lines = append(lines, formatComparison(label, summary.Outcome, previousOutcome))
+	}
+
+	return joinSystemLines(lines)
+}
+
+// GetWeeklyDigest summarizes the outcome of the seven days ending at now against the seven days before,
+// it is empty when the current month cannot be read
+func (ms *MessageService) GetWeeklyDigest(now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	summaries := make(map[time.Month]*domain.MonthSummary)
+	dailyOutcome := func(day time.Time) float64 {
+		summary, ok := summaries[day.Month()]
+		if !ok {
+			month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+			var err error
+			if summary, err = ms.ledger.MonthSummary(month); err != nil {
+				log.Warn("failed to read month for the weekly digest: ", err)
+			}
+			summaries[day.Month()] = summary
+		}
+		if summary == nil || day.Day() > len(summary.DailyOutcomes) {
+			return 0
+		}
+		return summary.DailyOutcomes[day.Day()-1]
+	}
+
+	var outcome, previousOutcome, busiestOutcome float64
+	var busiestDay time.Time
+	for i := 0; i < 7; i++ {
+		day := today.AddDate(0, 0, -i)
+		value := dailyOutcome(day)
+		outcome += value
+		if value > busiestOutcome {
+			busiestDay, busiestOutcome = day, value
+		}
+		previousOutcome += dailyOutcome(day.AddDate(0, 0, -7))
+	}
+	if summaries[today.Month()] == nil {
+		return ""
+	}
+
+	lines := []string{
+		fmt.Sprintf("weekly digest %s - %s", today.AddDate(0, 0, -6).Format("02/01"), today.Format("02/01")),
+		"outcome: " + utils.FormatMoney(outcome),
+		"average daily spend: " + utils.FormatMoney(outcome/7),
+	}
+	if busiestOutcome > 0 {
+		lines = append(lines, fmt.Sprintf("busiest day: %s (%s)", busiestDay.Format("02/01"), utils.FormatMoney(busiestOutcome)))
+	}
+	lines = append(lines, formatComparison("previous week", outcome, previousOutcome))
+	if balance, err := ms.ledger.Balance(); err == nil {
+		lines = append(lines, "balance: "+utils.FormatMoney(balance))
+	}
+
+	return joinSystemLines(lines)
+}
+
+func formatComparison(label string, current, previous float64) string {
+	comparison := fmt.Sprintf("vs %s: %s", label, formatDelta(current-previous))
+	if previous != 0 {
+		comparison += fmt.Sprintf(" (%+.0f%%)", (current-previous)/previous*100)
+	}
+	return comparison
+}
+
+func formatDelta(value float64) string {
+	if value > 0 {
+		return "+" + utils.FormatMoney(value)
+	}
+	return utils.FormatMoney(value)
+}
+
+func joinSystemLines(lines []string) string {
+	for i := range lines {
+		lines[i] = domain.SystemMessagePrefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (ms *MessageService) getBudgets() string {
+	budgets := ms.budgetService.Budgets()
+	if len(budgets) == 0 {
+		return domain.SystemMessagePrefix + "no budgets defined"
+	}
+
+	totals, err := ms.ledger.CategoryTotals()
+	if err != nil {
+		return SystemError
+	}
+
+	categories := make([]string, 0, len(budgets))
+	for category := range budgets {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var lines []string
+	for _, category := range categories {
+		lines = append(lines, fmt.Sprintf("%s%s: %s of %s (%.0f%%)", domain.SystemMessagePrefix, category,
+			utils.FormatMoney(totals[category]), utils.FormatMoney(budgets[category]), totals[category]/budgets[category]*100))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (ms *MessageService) setBudget(message *domain.Message) string {
+	budget, err := message.ToBudget()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	if err := ms.budgetService.SetBudget(budget); err != nil {
+		log.Error("failed to set budget: ", err)
+		return SystemError
+	}
+
+	return fmt.Sprintf("%sbudget for %s set to %s", domain.SystemMessagePrefix, budget.Category, utils.FormatMoney(budget.Limit))
+}
+
+func (ms *MessageService) setExchangeRate(message *domain.Message) string {
+	exchangeRate, err := message.ToExchangeRate()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	if err := ms.exchangeRates.SetRate(exchangeRate); err != nil {
+		log.Error("failed to set exchange rate: ", err)
+		return SystemError
+	}
+
+	return fmt.Sprintf("%sexchange rate for %s set to %s", domain.SystemMessagePrefix, exchangeRate.Currency, utils.FormatMoney(exchangeRate.Rate))
+}
+
+func (ms *MessageService) missingExchangeRate(transaction *domain.Transaction) string {
+	return fmt.Sprintf("%sno exchange rate for %s, set one with: cotacao %s 5,40", domain.SystemMessagePrefix, transaction.Currency, transaction.Currency)
+}
+
+// budgetWarning returns a warning when the transaction made its category cross 80% or 100% of the budget
+func (ms *MessageService) budgetWarning(transaction *domain.Transaction) string {
+	if transaction.IsIncome() || transaction.Category == "" {
+		return ""
+	}
+
+	// the totals are for the current month, a backdated transaction from another month does not change them
+	now := time.Now()
+	if day := transaction.Day(now); day.Year() != now.Year() || day.Month() != now.Month() {
+		return ""
+	}
+
+	limit, ok := ms.budgetService.Budget(transaction.Category)
+	if !ok {
+		return ""
+	}
+
+	totals, err := ms.ledger.CategoryTotals()
+	if err != nil {
+		log.Error("failed to check budget: ", err)
+		return ""
+	}
+
+	after := totals[transaction.Category]
+	before := after - math.Abs(transaction.Amount)
+	spent := fmt.Sprintf("(%s of %s)", utils.FormatMoney(after), utils.FormatMoney(limit))
+
+	switch {
+	case before < limit && after >= limit:
+		return fmt.Sprintf("%swarning: %s reached its budget %s", domain.SystemMessagePrefix, transaction.Category, spent)
+	case before < budgetAlertRatio*limit && after >= budgetAlertRatio*limit:
+		return fmt.Sprintf("%swarning: %s reached %.0f%% of its budget %s", domain.SystemMessagePrefix, transaction.Category, budgetAlertRatio*100, spent)
+	default:
+		return ""
+	}
+}
+
+func (ms *MessageService) addRecurring(message *domain.Message) string {
+	recurring, err := message.ToRecurring()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	if err := ms.recurringService.Add(recurring); err != nil {
+		log.Error("failed to add recurring transaction: ", err)
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "recurring transaction added: " + recurring.String()
+}
+
+func (ms *MessageService) getRecurringList() string {
+	recurrings := ms.recurringService.List()
+	if len(recurrings) == 0 {
+		return domain.SystemMessagePrefix + "no recurring transactions"
+	}
+
+	var lines []string
+	for i, recurring := range recurrings {
+		lines = append(lines, fmt.Sprintf("%s%d. %s", domain.SystemMessagePrefix, i+1, recurring.String()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (ms *MessageService) removeRecurring(message *domain.Message) string {
+	index, err := message.ToRecurringIndex()
+	if err != nil {
+		return domain.InvalidMessage
+	}
+
+	removed, err := ms.recurringService.Remove(index)
+	if errors.Is(err, domain.ErrInvalidRecurring) {
+		return domain.SystemMessagePrefix + fmt.Sprintf("recurring transaction %d not found", index)
+	}
+	if err != nil {
+		log.Error("failed to remove recurring transaction: ", err)
+		return SystemError
+	}
+
+	return domain.SystemMessagePrefix + "recurring transaction removed: " + removed.String()
+}
+
+// ProcessDueRecurring records the recurring transactions due at now and returns their announcements
+func (ms *MessageService) ProcessDueRecurring(now time.Time) []*domain.Message {
+	var announcements []*domain.Message
+	err := ms.recurringService.RunDue(now, func(recurring *domain.Recurring, day time.Time) error {
+		transaction := recurring.ToTransaction()
+		transaction.Date = day
+		if err := ms.exchangeRates.Convert(transaction); err != nil {
+			return err
+		}
+		if err := ms.ledger.RecordTransaction(transaction); err != nil {
+			return err
+		}
+
+		announcement := domain.SystemMessagePrefix + "recurring transaction processed: " + recurring.String()
+		if day.Format(dayLayout) != now.Format(dayLayout) {
+			announcement += " (missed on " + day.Format("02/01") + ")"
+		}
+		if warning := ms.budgetWarning(transaction); warning != "" {
+			announcement += "\n" + warning
+		}
+		announcements = append(announcements, ms.newReply(announcement))
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to process recurring transactions: ", err)
+	}
+
+	return announcements
+}
+
+func (ms *MessageService) GetDailyReminder() string {
+	needsReminder, err := ms.ledger.NeedsDailyReminder()
+	if err != nil {
+		return SystemError
+	}
+
+	if needsReminder {
+		return domain.SystemMessagePrefix + "you haven’t added any expenses today. Log them or set to zero if none."
+	}
+
+	return domain.InvalidMessage
 }
 
 func (ms *MessageService) newReply(content string) *domain.Message {
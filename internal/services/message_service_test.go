@@ -0,0 +1,695 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+type fakeLedger struct {
+	transactions   []*domain.Transaction
+	dailyOutcome   float64
+	balance        float64
+	notes          []string
+	categoryTotals map[string]float64
+	// summaries are keyed by month, e.g. "03/2026"
+	summaries map[string]*domain.MonthSummary
+	err       error
+}
+
+func (fl *fakeLedger) RecordTransaction(transaction *domain.Transaction) error {
+	if fl.err != nil {
+		return fl.err
+	}
+	fl.transactions = append(fl.transactions, transaction)
+	if !transaction.IsIncome() {
+		if fl.categoryTotals == nil {
+			fl.categoryTotals = make(map[string]float64)
+		}
+		fl.categoryTotals[transaction.Category] -= transaction.Amount
+	}
+	return nil
+}
+
+func (fl *fakeLedger) UndoLastTransaction() (*domain.Transaction, error) {
+	if len(fl.transactions) == 0 {
+		return nil, domain.ErrNothingToUndo
+	}
+	last := fl.transactions[len(fl.transactions)-1]
+	fl.transactions = fl.transactions[:len(fl.transactions)-1]
+	return last, fl.err
+}
+
+func (fl *fakeLedger) DailyOutcome() (float64, error) {
+	return fl.dailyOutcome, fl.err
+}
+
+func (fl *fakeLedger) Balance() (float64, error) {
+	return fl.balance, fl.err
+}
+
+func (fl *fakeLedger) DailyNotes() ([]string, error) {
+	return fl.notes, fl.err
+}
+
+func (fl *fakeLedger) RemoveDailyNote(index int) (*domain.Transaction, error) {
+	if index < 1 || index > len(fl.notes) {
+		return nil, domain.ErrNoteNotFound
+	}
+	removed, err := domain.ParseNote(fl.notes[index-1])
+	if err != nil {
+		return nil, err
+	}
+	fl.notes = append(fl.notes[:index-1], fl.notes[index:]...)
+	return removed, fl.err
+}
+
+func (fl *fakeLedger) EditDailyNote(index int, transaction *domain.Transaction) (*domain.Transaction, error) {
+	if index < 1 || index > len(fl.notes) {
+		return nil, domain.ErrNoteNotFound
+	}
+	replaced, err := domain.ParseNote(fl.notes[index-1])
+	if err != nil {
+		return nil, err
+	}
+	fl.notes[index-1] = transaction.Note()
+	return replaced, fl.err
+}
+
+func (fl *fakeLedger) CategoryTotals() (map[string]float64, error) {
+	return fl.categoryTotals, fl.err
+}
+
+func (fl *fakeLedger) MonthSummary(month time.Time) (*domain.MonthSummary, error) {
+	summary, ok := fl.summaries[month.Format("01/2006")]
+	if !ok {
+		return nil, errors.New("no yearly sheet")
+	}
+	return summary, fl.err
+}
+
+func (fl *fakeLedger) Transactions(from, to time.Time) ([]*domain.Transaction, error) {
+	var transactions []*domain.Transaction
+	for _, transaction := range fl.transactions {
+		if !transaction.Date.Before(from) && !transaction.Date.After(to) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions, fl.err
+}
+
+func (fl *fakeLedger) ZeroDaily() error {
+	if len(fl.notes) > 0 {
+		return domain.ErrDailyHasNotes
+	}
+	fl.dailyOutcome = 0
+	return fl.err
+}
+
+func (fl *fakeLedger) NeedsDailyReminder() (bool, error) {
+	return len(fl.notes) == 0 && fl.dailyOutcome != 0, fl.err
+}
+
+func newTestMessageService(t *testing.T, ledger Ledger) *MessageService {
+	t.Helper()
+
+	appConfig := &configuration.ApplicationConfig{}
+	appConfig.Budgets.FilePath = filepath.Join(t.TempDir(), "budgets.json")
+	appConfig.Recurring.FilePath = filepath.Join(t.TempDir(), "recurring.json")
+	appConfig.Currency.FilePath = filepath.Join(t.TempDir(), "rates.json")
+	bs, err := NewBudgetService(appConfig)
+	require.NoError(t, err)
+	rs, err := NewRecurringService(appConfig)
+	require.NoError(t, err)
+	ers, err := NewExchangeRateService(appConfig)
+	require.NoError(t, err)
+
+	return NewMessageService(context.Background(), appConfig, ledger, nil, NewMessageInterpreterService(), bs, rs, ers)
+}
+
+func TestMessageService_ProcessAndReply(t *testing.T) {
+
+	_ = t.Run("system message", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: domain.SystemMessagePrefix + "processed"})
+
+		// assert
+		_ = assert.Nil(t, reply)
+	})
+
+	_ = t.Run("outcome", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "-30,50 / cerveja"})
+
+		// assert
+		_ = assert.Equal(t, domain.SystemMessagePrefix+"processed -30.50 / cerveja", reply.Message)
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, -30.5, ledger.transactions[0].Amount)
+		_ = assert.Equal(t, "cerveja", ledger.transactions[0].Description)
+	})
+
+	_ = t.Run("interpreted income", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "vendi um produto por 200 reais"})
+
+		// assert
+		_ = assert.Contains(t, reply.Message, "processed")
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, 200.0, ledger.transactions[0].Amount)
+	})
+
+	_ = t.Run("ledger error", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{err: errors.New("unavailable")})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "-30 / cerveja"})
+
+		// assert
+		_ = assert.Equal(t, SystemError, reply.Message)
+	})
+
+	_ = t.Run("balance", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{balance: 1234.5})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "Saldo"})
+
+		// assert
+		_ = assert.Equal(t, domain.SystemMessagePrefix+"R$ 1.234,50", reply.Message)
+	})
+
+	_ = t.Run("outcome with category", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+
+		// act
+		_ = ms.ProcessAndReply(&domain.Message{Message: "-45 / mercado #Alimentacao"})
+
+		// assert
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, "mercado", ledger.transactions[0].Description)
+		_ = assert.Equal(t, "alimentacao", ledger.transactions[0].Category)
+	})
+
+	_ = t.Run("categories", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{categoryTotals: map[string]float64{
+			"transporte":  25,
+			"":            10,
+			"alimentacao": 145.5,
+		}})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "categorias"})
+
+		// assert
+		_ = assert.Equal(t, "sys: alimentacao: R$ 145,50\nsys: transporte: R$ 25,00\nsys: sem categoria: R$ 10,00", reply.Message)
+	})
+
+	_ = t.Run("set as zero with notes", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{notes: []string{"30.00 - cerveja"}})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "zerar"})
+
+		// assert
+		_ = assert.Equal(t, domain.SystemMessagePrefix+"daily value has notes, please remove them before setting as zero", reply.Message)
+	})
+
+	_ = t.Run("numbered notes", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{notes: []string{"30.00 - cerveja", "25.00 - uber #transporte"}})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "notas"})
+
+		// assert
+		_ = assert.Equal(t, "sys: 1. 30.00 - cerveja\nsys: 2. 25.00 - uber #transporte", reply.Message)
+	})
+
+	_ = t.Run("remove and edit notes", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{notes: []string{"30.00 - cerveja", "250.00 - uber"}}
+		ms := newTestMessageService(t, ledger)
+
+		// act
+		edit := ms.ProcessAndReply(&domain.Message{Message: "editar 2 -25 / uber #transporte"})
+		remove := ms.ProcessAndReply(&domain.Message{Message: "Remover 1"})
+		missing := ms.ProcessAndReply(&domain.Message{Message: "remover 3"})
+
+		// assert
+		_ = assert.Equal(t, "sys: replaced 250.00 - uber with 25.00 - uber #transporte", edit.Message)
+		_ = assert.Equal(t, "sys: removed 30.00 - cerveja", remove.Message)
+		_ = assert.Equal(t, "sys: note 3 not found", missing.Message)
+		_ = assert.Equal(t, []string{"25.00 - uber #transporte"}, ledger.notes)
+	})
+
+	_ = t.Run("undo", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		_ = ms.ProcessAndReply(&domain.Message{Message: "-300 / cafe"})
+
+		// act
+		undo := ms.ProcessAndReply(&domain.Message{Message: "Desfazer"})
+		nothing := ms.ProcessAndReply(&domain.Message{Message: "desfazer"})
+
+		// assert
+		_ = assert.Equal(t, "sys: undone -300 / cafe", undo.Message)
+		_ = assert.Equal(t, "sys: nothing to undo", nothing.Message)
+		_ = assert.Empty(t, ledger.transactions)
+	})
+
+	_ = t.Run("invalid message", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "hello"})
+
+		// assert
+		_ = assert.Equal(t, domain.InvalidMessage+": hello", reply.Message)
+	})
+}
+
+func TestMessageService_Budgets(t *testing.T) {
+
+	_ = t.Run("set budget from chat", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{categoryTotals: map[string]float64{"mercado": 200}})
+
+		// act
+		setReply := ms.ProcessAndReply(&domain.Message{Message: "Orçamento mercado 800"})
+		listReply := ms.ProcessAndReply(&domain.Message{Message: "orcamentos"})
+
+		// assert
+		_ = assert.Equal(t, "sys: budget for mercado set to R$ 800,00", setReply.Message)
+		_ = assert.Equal(t, "sys: mercado: R$ 200,00 of R$ 800,00 (25%)", listReply.Message)
+	})
+
+	_ = t.Run("budget persists across restarts", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+		_ = ms.ProcessAndReply(&domain.Message{Message: "orcamento mercado 800"})
+
+		// act
+		bs, err := NewBudgetService(ms.appConfig)
+
+		// assert
+		_ = assert.NoError(t, err)
+		limit, ok := bs.Budget("mercado")
+		_ = assert.True(t, ok)
+		_ = assert.Equal(t, 800.0, limit)
+	})
+
+	_ = t.Run("a failed save keeps the previous budget", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{categoryTotals: map[string]float64{"mercado": 200}})
+		_ = ms.ProcessAndReply(&domain.Message{Message: "orcamento mercado 800"})
+		ms.appConfig.Budgets.FilePath = filepath.Join(t.TempDir(), "missing", "budgets.json")
+
+		// act
+		setReply := ms.ProcessAndReply(&domain.Message{Message: "orcamento mercado 400"})
+		listReply := ms.ProcessAndReply(&domain.Message{Message: "orcamentos"})
+
+		// assert
+		_ = assert.Equal(t, SystemError, setReply.Message)
+		_ = assert.Equal(t, "sys: mercado: R$ 200,00 of R$ 800,00 (25%)", listReply.Message)
+	})
+
+	_ = t.Run("warns when crossing thresholds", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{categoryTotals: map[string]float64{"mercado": 700}})
+		ms.appConfig.Budgets.Limits = map[string]float64{"mercado": 1000}
+
+		// act
+		below := ms.ProcessAndReply(&domain.Message{Message: "-50 / feira #mercado"})
+		eighty := ms.ProcessAndReply(&domain.Message{Message: "-60 / feira #mercado"})
+		hundred := ms.ProcessAndReply(&domain.Message{Message: "-200 / feira #mercado"})
+		above := ms.ProcessAndReply(&domain.Message{Message: "-10 / feira #mercado"})
+
+		// assert
+		_ = assert.Equal(t, "sys: processed -50 / feira #mercado", below.Message)
+		_ = assert.Equal(t, "sys: processed -60 / feira #mercado\nsys: warning: mercado reached 80% of its budget (R$ 810,00 of R$ 1.000,00)", eighty.Message)
+		_ = assert.Equal(t, "sys: processed -200 / feira #mercado\nsys: warning: mercado reached its budget (R$ 1.010,00 of R$ 1.000,00)", hundred.Message)
+		_ = assert.Equal(t, "sys: processed -10 / feira #mercado", above.Message)
+	})
+}
+
+func TestMessageService_Recurring(t *testing.T) {
+
+	_ = t.Run("add, list and remove", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		addRent := ms.ProcessAndReply(&domain.Message{Message: "recorrente -1500 / aluguel dia 5"})
+		addStreaming := ms.ProcessAndReply(&domain.Message{Message: "Recorrente -55,90 / netflix #lazer toda segunda-feira"})
+		remove := ms.ProcessAndReply(&domain.Message{Message: "remover recorrente 1"})
+		list := ms.ProcessAndReply(&domain.Message{Message: "recorrentes"})
+
+		// assert
+		_ = assert.Equal(t, "sys: recurring transaction added: -1500 / aluguel (dia 5)", addRent.Message)
+		_ = assert.Equal(t, "sys: recurring transaction added: -55.9 / netflix #lazer (toda segunda)", addStreaming.Message)
+		_ = assert.Equal(t, "sys: recurring transaction removed: -1500 / aluguel (dia 5)", remove.Message)
+		_ = assert.Equal(t, "sys: 1. -55.9 / netflix #lazer (toda segunda)", list.Message)
+	})
+
+	_ = t.Run("posts due transactions once", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Recurring.Hour = 8
+		_ = ms.ProcessAndReply(&domain.Message{Message: "recorrente -1500 / aluguel dia 31"})
+		_ = ms.ProcessAndReply(&domain.Message{Message: "recorrente 5000 / salario dia 5"})
+		lastDayOfFebruary := time.Date(2026, 2, 28, 9, 0, 0, 0, time.Local)
+
+		// act
+		beforeHour := ms.ProcessDueRecurring(lastDayOfFebruary.Add(-2 * time.Hour))
+		due := ms.ProcessDueRecurring(lastDayOfFebruary)
+		again := ms.ProcessDueRecurring(lastDayOfFebruary.Add(time.Hour))
+
+		// assert
+		_ = assert.Empty(t, beforeHour)
+		_ = assert.Len(t, due, 1)
+		_ = assert.Equal(t, "sys: recurring transaction processed: -1500 / aluguel (dia 31)", due[0].Message)
+		_ = assert.Empty(t, again)
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, -1500.0, ledger.transactions[0].Amount)
+	})
+
+	_ = t.Run("posts the runs missed while the bot was down", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		_ = ms.ProcessAndReply(&domain.Message{Message: "recorrente -1500 / aluguel dia 5"})
+		_ = ms.ProcessDueRecurring(time.Date(2026, 2, 5, 9, 0, 0, 0, time.Local))
+
+		// act
+		due := ms.ProcessDueRecurring(time.Date(2026, 3, 7, 9, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Len(t, due, 1)
+		_ = assert.Equal(t, "sys: recurring transaction processed: -1500 / aluguel (dia 5) (missed on 05/03)", due[0].Message)
+		_ = assert.Len(t, ledger.transactions, 2)
+		_ = assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local), ledger.transactions[1].Date)
+	})
+
+	_ = t.Run("a failed save keeps the list unchanged", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+		_ = ms.ProcessAndReply(&domain.Message{Message: "recorrente -1500 / aluguel dia 5"})
+		ms.appConfig.Recurring.FilePath = filepath.Join(t.TempDir(), "missing", "recurring.json")
+
+		// act
+		add := ms.ProcessAndReply(&domain.Message{Message: "recorrente -55,90 / netflix toda segunda"})
+		remove := ms.ProcessAndReply(&domain.Message{Message: "remover recorrente 1"})
+		list := ms.ProcessAndReply(&domain.Message{Message: "recorrentes"})
+
+		// assert
+		_ = assert.Equal(t, SystemError, add.Message)
+		_ = assert.Equal(t, SystemError, remove.Message)
+		_ = assert.Equal(t, "sys: 1. -1500 / aluguel (dia 5)", list.Message)
+	})
+}
+
+func TestMessageService_Currency(t *testing.T) {
+
+	_ = t.Run("converts with the configured rate", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Currency.Rates = map[string]float64{"usd": 5}
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "-15 USD / taxi"})
+
+		// assert
+		_ = assert.Equal(t, "sys: processed -15 USD / taxi (-R$ 75,00)", reply.Message)
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, "75.00 - taxi (USD 15.00)", ledger.transactions[0].Note())
+	})
+
+	_ = t.Run("rate set from chat", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+
+		// act
+		missing := ms.ProcessAndReply(&domain.Message{Message: "-10 € / museu"})
+		setRate := ms.ProcessAndReply(&domain.Message{Message: "Cotação EUR 6,10"})
+		converted := ms.ProcessAndReply(&domain.Message{Message: "-10 € / museu"})
+
+		// assert
+		_ = assert.Equal(t, "sys: no exchange rate for EUR, set one with: cotacao EUR 5,40", missing.Message)
+		_ = assert.Equal(t, "sys: exchange rate for EUR set to R$ 6,10", setRate.Message)
+		_ = assert.Equal(t, "sys: processed -10 € / museu (-R$ 61,00)", converted.Message)
+		_ = assert.Len(t, ledger.transactions, 1)
+	})
+
+	_ = t.Run("a failed save keeps the previous rate", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		_ = ms.ProcessAndReply(&domain.Message{Message: "cotacao EUR 6,10"})
+		ms.appConfig.Currency.FilePath = filepath.Join(t.TempDir(), "missing", "rates.json")
+
+		// act
+		setRate := ms.ProcessAndReply(&domain.Message{Message: "cotacao EUR 7"})
+		converted := ms.ProcessAndReply(&domain.Message{Message: "-10 € / museu"})
+
+		// assert
+		_ = assert.Equal(t, SystemError, setRate.Message)
+		_ = assert.Equal(t, "sys: processed -10 € / museu (-R$ 61,00)", converted.Message)
+	})
+}
+
+func TestMessageService_Report(t *testing.T) {
+
+	_ = t.Run("month report compared with the previous month", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{summaries: map[string]*domain.MonthSummary{
+			"03/2025": {Income: 5000, Outcome: 600, ClosingBalance: 3200.5, DailyOutcomes: []float64{100, 350, 150}},
+			"02/2025": {Outcome: 500},
+		}})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "Relatório 03/2025"})
+
+		// assert
+		_ = assert.Equal(t, "sys: report 03/2025\n"+
+			"sys: income: R$ 5.000,00\n"+
+			"sys: outcome: R$ 600,00\n"+
+			"sys: closing balance: R$ 3.200,50\n"+
+			"sys: busiest day: 02/03 (R$ 350,00)\n"+
+			"sys: average daily spend: R$ 200,00\n"+
+			"sys: vs 02/2025: +R$ 100,00 (+20%)", reply.Message)
+	})
+
+	_ = t.Run("current month compared with the same days of the previous one", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{summaries: map[string]*domain.MonthSummary{
+			"03/2025": {Outcome: 300, DailyOutcomes: []float64{100, 200}},
+			"02/2025": {Outcome: 1000, DailyOutcomes: []float64{50, 50, 900}},
+		}})
+
+		// act
+		report := ms.monthReport("report", time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 3, 2, 18, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Contains(t, report, "sys: vs 02/2025 (first 2 days): +R$ 200,00 (+200%)")
+	})
+
+	_ = t.Run("future month", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "relatorio 01/" + strconv.Itoa(time.Now().Year()+1)})
+
+		// assert
+		_ = assert.Equal(t, "sys: that month has not started yet", reply.Message)
+	})
+
+	_ = t.Run("without the previous month", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{summaries: map[string]*domain.MonthSummary{
+			"01/2025": {Income: 100, DailyOutcomes: []float64{0, 0}},
+		}})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "relatorio 1/2025"})
+
+		// assert
+		_ = assert.Equal(t, "sys: report 01/2025\n"+
+			"sys: income: R$ 100,00\n"+
+			"sys: outcome: R$ 0,00\n"+
+			"sys: closing balance: R$ 0,00\n"+
+			"sys: average daily spend: R$ 0,00", reply.Message)
+	})
+
+	_ = t.Run("missing month", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "relatorio 13/2025"})
+
+		// assert
+		_ = assert.Equal(t, domain.InvalidMessage, reply.Message)
+	})
+}
+
+func TestMessageService_Digests(t *testing.T) {
+
+	_ = t.Run("weekly digest across months", func(t *testing.T) {
+		// arrange
+		february := make([]float64, 28)
+		february[25], february[20] = 40, 100
+		ms := newTestMessageService(t, &fakeLedger{balance: 900, summaries: map[string]*domain.MonthSummary{
+			"03/2026": {DailyOutcomes: []float64{10, 60, 0}},
+			"02/2026": {DailyOutcomes: february},
+		}})
+
+		// act
+		digest := ms.GetWeeklyDigest(time.Date(2026, 3, 3, 20, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Equal(t, "sys: weekly digest 25/02 - 03/03\n"+
+			"sys: outcome: R$ 110,00\n"+
+			"sys: average daily spend: R$ 15,71\n"+
+			"sys: busiest day: 02/03 (R$ 60,00)\n"+
+			"sys: vs previous week: +R$ 10,00 (+10%)\n"+
+			"sys: balance: R$ 900,00", digest)
+	})
+
+	_ = t.Run("monthly digest of the previous month", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{summaries: map[string]*domain.MonthSummary{
+			"12/2025": {Income: 10, Outcome: 20, DailyOutcomes: []float64{20}},
+		}})
+
+		// act
+		digest := ms.GetMonthlyDigest(time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.Equal(t, "sys: monthly digest 12/2025\n"+
+			"sys: income: R$ 10,00\n"+
+			"sys: outcome: R$ 20,00\n"+
+			"sys: closing balance: R$ 0,00\n"+
+			"sys: busiest day: 01/12 (R$ 20,00)\n"+
+			"sys: average daily spend: R$ 20,00", digest)
+	})
+
+	_ = t.Run("nothing to post without the sheet", func(t *testing.T) {
+		// arrange
+		ms := newTestMessageService(t, &fakeLedger{})
+
+		// act
+		weekly := ms.GetWeeklyDigest(time.Now())
+		monthly := ms.GetMonthlyDigest(time.Now())
+
+		// assert
+		_ = assert.Empty(t, weekly)
+		_ = assert.Empty(t, monthly)
+	})
+}
+
+func TestMessageService_Export(t *testing.T) {
+
+	_ = t.Run("writes the period to a csv attachment", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{transactions: []*domain.Transaction{
+			{Amount: -20, Description: "padaria", Date: time.Date(2025, 3, 12, 0, 0, 0, 0, time.Local)},
+			{Amount: -30, Description: "uber", Date: time.Date(2024, 3, 12, 0, 0, 0, 0, time.Local)},
+		}}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Exports.Dir = t.TempDir()
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "Exportar 2025"})
+
+		// assert
+		_ = assert.Equal(t, "sys: exported 1 transactions from 01/01/2025 to 31/12/2025", reply.Message)
+		_ = assert.Equal(t, filepath.Join(ms.appConfig.Exports.Dir, "transacoes-20250101-20251231.csv"), reply.Attachment)
+		csv, err := os.ReadFile(reply.Attachment)
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "date,direction,amount,description,category,author\n2025-03-12,outcome,20.00,padaria,,\n", string(csv))
+	})
+}
+
+func TestMessageService_Ai(t *testing.T) {
+
+	_ = t.Run("records a validated answer", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Ai.IsEnabled = true
+		ms.aiService = newTestOllamaAIService(t, `{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja", "category": "lazer"}`, nil)
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Sender: "Ana", Message: "comprei uma cerveja 30 reais"})
+
+		// assert
+		_ = assert.Equal(t, "sys: processed -30 / cerveja #lazer", reply.Message)
+		_ = assert.Len(t, ledger.transactions, 1)
+		_ = assert.Equal(t, "Ana", ledger.transactions[0].Author)
+	})
+
+	_ = t.Run("never records an invalid answer", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Ai.IsEnabled = true
+		ms.aiService = newTestOllamaAIService(t, `{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "", "category": ""}`, nil)
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Message: "oi tudo bem"})
+
+		// assert
+		_ = assert.Equal(t, "sys: invalid message: oi tudo bem", reply.Message)
+		_ = assert.Empty(t, ledger.transactions)
+	})
+
+	_ = t.Run("falls back to the interpreter while the ai is unavailable", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		ms := newTestMessageService(t, ledger)
+		ms.appConfig.Ai.IsEnabled = true
+		ms.aiService = newTestOllamaAIService(t, `{"is_transaction": false}`, nil)
+		ms.aiService.breaker.state = circuitOpen
+		ms.aiService.breaker.openedAt = time.Now()
+
+		// act
+		reply := ms.ProcessAndReply(&domain.Message{Sender: "Ana", Message: "-30 / cerveja"})
+
+		// assert
+		_ = assert.Equal(t, "sys: processed -30 / cerveja", reply.Message)
+		_ = assert.Len(t, ledger.transactions, 1)
+	})
+}
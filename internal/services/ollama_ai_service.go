@@ -1,44 +1,77 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings" // Add this import
+	"strings"
+	"time"
 
 	"github.com/vitortenor/sheet-bot/internal/client"
 	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
 )
 
+// ErrAiUnavailable is returned while the circuit breaker bypasses an unhealthy model
+var ErrAiUnavailable = errors.New("ai is unavailable")
+
 type OllamaAIService struct {
 	appConfig *configuration.ApplicationConfig
 	client    *client.OllamaAIClient
+	breaker   *circuitBreaker
 }
 
-var PROMPT = "Your task is to format a text message based on whether it describes an income or expense transaction. Follow these rules carefully:\n\n1. **Expense:** If the message describes a purchase or spending action (e.g., 'comprei uma água por 5 reais'), output the amount as a negative number, followed by the item or description. \n   - Example: 'comprei uma cerveja 30 reais' → `-30 / cerveja`\n\n2. **Income:** If the message describes earning or receiving money (e.g., 'vendi um produto por 200 reais'), output the amount as a positive number, followed by the description. \n   - Example: 'vendi um produto por 200 reais' → `200 / vendi um produto`\n\n3. **Invalid message:** If the input does not clearly describe a valid transaction with an amount, or if the input is one of the following: \"diario\", \"diario-detail\", \"zerar\", or \"saldo\", return `false`. \n   - Example: 'comrpe aaa' → `false`\n\n4. **Specific conditions:** If the input message is exactly \"diario\", \"diario-detail\", \"zerar\", or \"saldo\", return `false`.\n\nProcess the following input message: **'%s'**\n\nReturn only the formatted result, without explanations or additional text."
+var PROMPT = "Your task is to read a text message in Portuguese and tell whether it describes an income or expense transaction. Follow these rules carefully:\n\n1. **Expense:** If the message describes a purchase or spending action (e.g., 'comprei uma água por 5 reais'), set `is_transaction` to true, `direction` to \"outcome\", `amount` to the positive value and `description` to the item.\n   - Example: 'comprei uma cerveja 30 reais' → `{\"is_transaction\": true, \"amount\": 30, \"direction\": \"outcome\", \"description\": \"cerveja\", \"category\": \"\"}`\n\n2. **Income:** If the message describes earning or receiving money (e.g., 'vendi um produto por 200 reais'), set `direction` to \"income\".\n   - Example: 'vendi um produto por 200 reais' → `{\"is_transaction\": true, \"amount\": 200, \"direction\": \"income\", \"description\": \"vendi um produto\", \"category\": \"\"}`\n\n3. **Category:** Only fill `category` with a single lowercase word when the message names one, otherwise leave it empty.\n\n4. **Invalid message:** If the input does not clearly describe a valid transaction with an amount, or if the input is one of the following: \"diario\", \"notas\", \"zerar\", or \"saldo\", set `is_transaction` to false.\n   - Example: 'comrpe aaa' → `{\"is_transaction\": false, \"amount\": 0, \"direction\": \"outcome\", \"description\": \"\", \"category\": \"\"}`\n\nProcess the following input message: **'%s'**\n\nReturn only the JSON object."
+
+// aiTransactionSchema constrains the model answer to a domain.AiTransaction
+var aiTransactionSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"is_transaction": map[string]interface{}{"type": "boolean"},
+		"amount":         map[string]interface{}{"type": "number"},
+		"direction":      map[string]interface{}{"type": "string", "enum": []string{"income", "outcome"}},
+		"description":    map[string]interface{}{"type": "string"},
+		"category":       map[string]interface{}{"type": "string"},
+	},
+	"required":             []string{"is_transaction", "amount", "direction", "description", "category"},
+	"additionalProperties": false,
+}
 
 func NewOllamaAIService(appConfig *configuration.ApplicationConfig, oac *client.OllamaAIClient) *OllamaAIService {
 	return &OllamaAIService{
 		appConfig: appConfig,
 		client:    oac,
+		breaker:   newCircuitBreaker("ollama", appConfig.Ai.FailureThreshold, time.Duration(appConfig.Ai.Cooldown)*time.Second),
 	}
 }
 
-func (oas *OllamaAIService) GetOllamaAIResponse(message string) string {
+// ExtractTransaction asks the model for a structured transaction and validates it,
+// domain.ErrNotATransaction is returned when the message does not describe one
+// and ErrAiUnavailable while the model is being bypassed after repeated failures
+func (oas *OllamaAIService) ExtractTransaction(ctx context.Context, message string) (*domain.AiTransaction, error) {
+	if !oas.breaker.Allow() {
+		return nil, ErrAiUnavailable
+	}
+
 	promptMessage := fmt.Sprintf(PROMPT, message)
-	response, err := oas.client.GetOllamaAIResponse(oas.appConfig.Ai.ModelName, promptMessage)
+	response, err := oas.client.GetOllamaAIResponse(ctx, oas.appConfig.Ai.ModelName, promptMessage, aiTransactionSchema)
 	if err != nil {
-		return fmt.Sprintf("failed to generate text: %s", err)
+		oas.breaker.Failure()
+		return nil, fmt.Errorf("failed to generate text: %w", err)
 	}
+	// an answer failing validation is the model misbehaving, not being unhealthy
+	oas.breaker.Success()
 
 	type responseObj struct {
 		Response string `json:"response"`
 	}
 
 	var r responseObj
-	err = json.NewDecoder(strings.NewReader(response)).Decode(&r) // Convert the response to a reader
+	err = json.NewDecoder(strings.NewReader(response)).Decode(&r)
 	if err != nil {
-		return fmt.Sprintf("failed to decode response: %s", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return r.Response
+	return domain.ParseAiTransaction(r.Response)
 }
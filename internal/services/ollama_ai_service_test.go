@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/client"
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+// newTestOllamaAIService talks to a fake Ollama server answering every request with the given model output
+func newTestOllamaAIService(t *testing.T, answer string, requests *[]map[string]interface{}) *OllamaAIService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if requests != nil {
+			*requests = append(*requests, body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"response": answer, "done": true})
+	}))
+	t.Cleanup(server.Close)
+
+	appConfig := &configuration.ApplicationConfig{}
+	appConfig.Ai.ModelName = "llama3.2"
+	return NewOllamaAIService(appConfig, client.NewOllamaAIClient(server.URL, time.Second, 0))
+}
+
+func TestOllamaAIService_ExtractTransaction(t *testing.T) {
+
+	_ = t.Run("requests the json schema", func(t *testing.T) {
+		// arrange
+		var requests []map[string]interface{}
+		oas := newTestOllamaAIService(t, `{"is_transaction": true, "amount": 30, "direction": "outcome", "description": "cerveja", "category": ""}`, &requests)
+
+		// act
+		transaction, err := oas.ExtractTransaction(context.Background(), "comprei uma cerveja 30 reais")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, "-30 / cerveja", transaction.Message())
+		_ = assert.Len(t, requests, 1)
+		_ = assert.Equal(t, "llama3.2", requests[0]["model"])
+		format, _ := requests[0]["format"].(map[string]interface{})
+		_ = assert.Equal(t, "object", format["type"])
+		_ = assert.Equal(t, false, format["additionalProperties"])
+	})
+
+	_ = t.Run("free text answer is rejected", func(t *testing.T) {
+		// arrange
+		oas := newTestOllamaAIService(t, "Claro! A transação é: -30 / cerveja", nil)
+
+		// act
+		_, err := oas.ExtractTransaction(context.Background(), "comprei uma cerveja 30 reais")
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrInvalidAiTransaction)
+	})
+
+	_ = t.Run("bypasses the model after repeated failures", func(t *testing.T) {
+		// arrange
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+		appConfig := &configuration.ApplicationConfig{}
+		appConfig.Ai.FailureThreshold = 2
+		oas := NewOllamaAIService(appConfig, client.NewOllamaAIClient(server.URL, time.Second, 0))
+
+		// act
+		_, firstErr := oas.ExtractTransaction(context.Background(), "comprei uma cerveja 30 reais")
+		_, secondErr := oas.ExtractTransaction(context.Background(), "comprei uma cerveja 30 reais")
+		_, thirdErr := oas.ExtractTransaction(context.Background(), "comprei uma cerveja 30 reais")
+
+		// assert
+		_ = assert.Error(t, firstErr)
+		_ = assert.NotErrorIs(t, secondErr, ErrAiUnavailable)
+		_ = assert.ErrorIs(t, thirdErr, ErrAiUnavailable)
+		_ = assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/vitortenor/sheet-bot/internal/utils"
+)
+
+// overrideStore keeps the values set from chat over the configured ones, persisted as a json object in its file
+type overrideStore struct {
+	// name is what the file holds, e.g. "budgets"
+	name string
+	// path points at the configured file so a later configuration change is followed
+	path   *string
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+func newOverrideStore(name string, path *string) (*overrideStore, error) {
+	store := &overrideStore{
+		name:   name,
+		path:   path,
+		values: make(map[string]float64),
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// merge returns the configured values with their keys normalized by key, overridden by the stored ones
+func (store *overrideStore) merge(configured map[string]float64, key func(string) string) map[string]float64 {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	merged := make(map[string]float64)
+	for name, value := range configured {
+		merged[key(name)] = value
+	}
+	for name, value := range store.values {
+		merged[name] = value
+	}
+	return merged
+}
+
+func (store *overrideStore) set(name string, value float64) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// the value only takes effect once it is saved
+	values := make(map[string]float64, len(store.values)+1)
+	for existing, previous := range store.values {
+		values[existing] = previous
+	}
+	values[name] = value
+	if err := store.save(values); err != nil {
+		return err
+	}
+
+	store.values = values
+	return nil
+}
+
+func (store *overrideStore) load() error {
+	if *store.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(*store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &store.values)
+}
+
+func (store *overrideStore) save(values map[string]float64) error {
+	if *store.path == "" {
+		return fmt.Errorf("%s file path is not configured", store.name)
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomically(*store.path, data)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+	"github.com/vitortenor/sheet-bot/internal/utils"
+)
+
+type RecurringService struct {
+	appConfig  *configuration.ApplicationConfig
+	mutex      sync.Mutex
+	recurrings []*domain.Recurring
+}
+
+func NewRecurringService(appConfig *configuration.ApplicationConfig) (*RecurringService, error) {
+	rs := &RecurringService{
+		appConfig: appConfig,
+	}
+
+	if err := rs.load(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (rs *RecurringService) Add(recurring *domain.Recurring) error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	recurrings := append(rs.recurrings[:len(rs.recurrings):len(rs.recurrings)], recurring)
+	if err := rs.save(recurrings); err != nil {
+		return err
+	}
+	rs.recurrings = recurrings
+	return nil
+}
+
+func (rs *RecurringService) List() []domain.Recurring {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	recurrings := make([]domain.Recurring, 0, len(rs.recurrings))
+	for _, recurring := range rs.recurrings {
+		recurrings = append(recurrings, *recurring)
+	}
+	return recurrings
+}
+
+// Remove deletes the recurring transaction at the one-based position shown by List
+func (rs *RecurringService) Remove(index int) (*domain.Recurring, error) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if index < 1 || index > len(rs.recurrings) {
+		return nil, domain.ErrInvalidRecurring
+	}
+
+	removed := rs.recurrings[index-1]
+	// a new slice, removing in place would shift the current one too
+	recurrings := append(append([]*domain.Recurring{}, rs.recurrings[:index-1]...), rs.recurrings[index:]...)
+	if err := rs.save(recurrings); err != nil {
+		return nil, err
+	}
+	rs.recurrings = recurrings
+	return removed, nil
+}
+
+// RunDue calls run for every day a recurring transaction is due on up to now, including the ones missed while
+// the bot was down, and marks each day that succeeds as posted; a failed day is retried with the ones after it.
+// The marks are kept only once they are saved.
+func (rs *RecurringService) RunDue(now time.Time, run func(recurring *domain.Recurring, day time.Time) error) error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if now.Hour() < rs.appConfig.Recurring.Hour {
+		return nil
+	}
+
+	var errs []error
+	changed := false
+	marked := make([]*domain.Recurring, 0, len(rs.recurrings))
+	for _, recurring := range rs.recurrings {
+		next := *recurring
+		for _, day := range recurring.DueDays(now) {
+			if err := run(recurring, day); err != nil {
+				errs = append(errs, err)
+				break
+			}
+			next.MarkRun(day)
+			changed = true
+		}
+		marked = append(marked, &next)
+	}
+
+	if changed {
+		if err := rs.save(marked); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		rs.recurrings = marked
+	}
+	return errors.Join(errs...)
+}
+
+func (rs *RecurringService) load() error {
+	if rs.appConfig.Recurring.FilePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rs.appConfig.Recurring.FilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &rs.recurrings)
+}
+
+func (rs *RecurringService) save(recurrings []*domain.Recurring) error {
+	if rs.appConfig.Recurring.FilePath == "" {
+		return errors.New("recurring file path is not configured")
+	}
+
+	data, err := json.MarshalIndent(recurrings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomically(rs.appConfig.Recurring.FilePath, data)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func TestRecurringService_RunDue(t *testing.T) {
+	now := time.Date(2026, 3, 12, 9, 0, 0, 0, time.Local)
+
+	_ = t.Run("marks the posted days", func(t *testing.T) {
+		// arrange
+		appConfig := &configuration.ApplicationConfig{}
+		appConfig.Recurring.FilePath = filepath.Join(t.TempDir(), "recurring.json")
+		rs, err := NewRecurringService(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, rs.Add(&domain.Recurring{Amount: -50, Description: "academia", DayOfMonth: 12}))
+
+		// act
+		var runs int
+		err = rs.RunDue(now, func(_ *domain.Recurring, _ time.Time) error {
+			runs++
+			return nil
+		})
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 1, runs)
+		_ = assert.Equal(t, "2026-03-12", rs.List()[0].LastRun)
+	})
+
+	_ = t.Run("keeps the marks out when the save fails", func(t *testing.T) {
+		// arrange
+		appConfig := &configuration.ApplicationConfig{}
+		appConfig.Recurring.FilePath = filepath.Join(t.TempDir(), "recurring.json")
+		rs, err := NewRecurringService(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, rs.Add(&domain.Recurring{Amount: -50, Description: "academia", DayOfMonth: 12}))
+		appConfig.Recurring.FilePath = filepath.Join(t.TempDir(), "missing", "recurring.json")
+
+		// act
+		err = rs.RunDue(now, func(_ *domain.Recurring, _ time.Time) error {
+			return nil
+		})
+
+		// assert
+		_ = assert.Error(t, err)
+		_ = assert.Empty(t, rs.List()[0].LastRun)
+	})
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+const (
+	// a transaction request is a short json object
+	maxRequestBodyBytes = 16 << 10
+	httpReadTimeout     = 10 * time.Second
+	httpWriteTimeout    = 30 * time.Second
+	httpIdleTimeout     = 2 * time.Minute
+)
+
+type RestApiService struct {
+	context   context.Context
+	appConfig *configuration.ApplicationConfig
+	ledger    Ledger
+	// exchangeRates converts transactions given in a foreign currency
+	exchangeRates *ExchangeRateService
+}
+
+type transactionRequest struct {
+	Message     string   `json:"message"`
+	Amount      *float64 `json:"amount"`
+	Description string   `json:"description"`
+}
+
+type transactionResponse struct {
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewRestApiService(ctx context.Context, appConfig *configuration.ApplicationConfig, ledger Ledger,
+	ers *ExchangeRateService) *RestApiService {
+	return &RestApiService{
+		context:       ctx,
+		appConfig:     appConfig,
+		ledger:        ledger,
+		exchangeRates: ers,
+	}
+}
+
+func (ras *RestApiService) Run() error {
+	if ras.appConfig.Http.Token == "" {
+		return errors.New("http token is not configured")
+	}
+
+	server := &http.Server{
+		Addr:              ras.appConfig.Http.Address,
+		Handler:           ras.Handler(),
+		ReadHeaderTimeout: httpReadTimeout,
+		ReadTimeout:       httpReadTimeout,
+		WriteTimeout:      httpWriteTimeout,
+		IdleTimeout:       httpIdleTimeout,
+	}
+
+	go func() {
+		<-ras.context.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	log.Infof("http api listening on %s", ras.appConfig.Http.Address)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (ras *RestApiService) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /transactions", ras.postTransaction)
+	mux.HandleFunc("GET /balance", ras.getBalance)
+	mux.HandleFunc("GET /daily", ras.getDaily)
+	mux.HandleFunc("GET /daily/notes", ras.getDailyNotes)
+	mux.HandleFunc("POST /daily/zero", ras.postDailyZero)
+
+	return ras.authenticate(mux)
+}
+
+func (ras *RestApiService) authenticate(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + ras.appConfig.Http.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := []byte(r.Header.Get("Authorization"))
+		if ras.appConfig.Http.Token == "" || subtle.ConstantTimeCompare(received, expected) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ras *RestApiService) postTransaction(w http.ResponseWriter, r *http.Request) {
+	var request transactionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+		return
+	}
+
+	transaction, err := request.toTransaction()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := ras.exchangeRates.Convert(transaction); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "no exchange rate for " + transaction.Currency})
+		return
+	}
+
+	if err := ras.ledger.RecordTransaction(transaction); err != nil {
+		ras.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transactionResponse{
+		Amount:      transaction.Amount,
+		Description: transaction.Description,
+	})
+}
+
+func (ras *RestApiService) getBalance(w http.ResponseWriter, _ *http.Request) {
+	balance, err := ras.ledger.Balance()
+	if err != nil {
+		ras.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]float64{"balance": balance})
+}
+
+func (ras *RestApiService) getDaily(w http.ResponseWriter, _ *http.Request) {
+	dailyOutcome, err := ras.ledger.DailyOutcome()
+	if err != nil {
+		ras.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]float64{"daily_outcome": dailyOutcome})
+}
+
+func (ras *RestApiService) getDailyNotes(w http.ResponseWriter, _ *http.Request) {
+	notes, err := ras.ledger.DailyNotes()
+	if err != nil {
+		ras.writeError(w, err)
+		return
+	}
+
+	if notes == nil {
+		notes = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"notes": notes})
+}
+
+func (ras *RestApiService) postDailyZero(w http.ResponseWriter, _ *http.Request) {
+	if err := ras.ledger.ZeroDaily(); err != nil {
+		ras.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]float64{"daily_outcome": 0})
+}
+
+func (ras *RestApiService) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTransaction):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrDailyHasNotes):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+	default:
+		log.Error("error handling http request: ", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "system error"})
+	}
+}
+
+func (tr *transactionRequest) toTransaction() (*domain.Transaction, error) {
+	if tr.Message != "" {
+		// the same messages the chats accept
+		message := &domain.Message{Message: tr.Message}
+		message.Normalize()
+		if !message.IsIncomeOrOutcome() {
+			return nil, domain.ErrInvalidTransaction
+		}
+
+		transaction, err := message.ToTransaction()
+		if err != nil {
+			return nil, err
+		}
+		if breaksNote(transaction.Description) {
+			return nil, domain.ErrInvalidTransaction
+		}
+		return transaction, nil
+	}
+
+	if tr.Amount == nil || breaksNote(tr.Description) {
+		return nil, domain.ErrInvalidTransaction
+	}
+
+	transaction := &domain.Transaction{
+		Amount:      *tr.Amount,
+		Description: strings.TrimSpace(tr.Description),
+	}
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// breaksNote reports whether the description would not read back from the note,
+// a note holds one transaction per line and its author is the " (@" suffix
+func breaksNote(description string) bool {
+	return strings.ContainsAny(description, "\r\n") || strings.Contains(description, " (@")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error("error writing http response: ", err)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+)
+
+func newTestRestApiService(t *testing.T, ledger Ledger) *RestApiService {
+	t.Helper()
+
+	appConfig := &configuration.ApplicationConfig{}
+	appConfig.Http.Token = "secret"
+	ers, err := NewExchangeRateService(appConfig)
+	require.NoError(t, err)
+
+	return NewRestApiService(context.Background(), appConfig, ledger, ers)
+}
+
+func TestRestApiService_PostTransaction(t *testing.T) {
+
+	_ = t.Run("records the transaction", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		request := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{"message": "-30 / cerveja"}`))
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusCreated, response.Code)
+		_ = assert.Len(t, ledger.transactions, 1)
+	})
+
+	_ = t.Run("rejects an oversized body", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		body := `{"message": "-30 / cerveja", "description": "` + strings.Repeat("a", maxRequestBodyBytes) + `"}`
+		request := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusBadRequest, response.Code)
+		_ = assert.Empty(t, ledger.transactions)
+	})
+
+	_ = t.Run("rejects a message that is not a transaction", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		request := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{"message": "saldo / hoje"}`))
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusBadRequest, response.Code)
+		_ = assert.Empty(t, ledger.transactions)
+	})
+
+	_ = t.Run("rejects descriptions that would break the note", func(t *testing.T) {
+		bodies := []string{
+			`{"message": "-30 / cerveja (@Rui)"}`,
+			`{"amount": -30, "description": "cerveja\n45.00 - mercado"}`,
+			`{"amount": -30, "description": "cerveja\r"}`,
+			`{"amount": -30, "description": "cerveja (@Rui)"}`,
+		}
+		for _, body := range bodies {
+			// arrange
+			ledger := &fakeLedger{}
+			request := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+			request.Header.Set("Authorization", "Bearer secret")
+			response := httptest.NewRecorder()
+
+			// act
+			newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+			// assert
+			_ = assert.Equal(t, http.StatusBadRequest, response.Code, body)
+			_ = assert.Empty(t, ledger.transactions, body)
+		}
+	})
+}
+
+func TestRestApiService_Authenticate(t *testing.T) {
+
+	_ = t.Run("missing token", func(t *testing.T) {
+		// arrange
+		request := httptest.NewRequest(http.MethodGet, "/balance", nil)
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, &fakeLedger{}).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	_ = t.Run("wrong token", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{}
+		request := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{"message": "-30 / cerveja"}`))
+		request.Header.Set("Authorization", "Bearer wrong")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusUnauthorized, response.Code)
+		_ = assert.Empty(t, ledger.transactions)
+	})
+}
+
+func TestRestApiService_GetBalance(t *testing.T) {
+	// arrange
+	request := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response := httptest.NewRecorder()
+
+	// act
+	newTestRestApiService(t, &fakeLedger{balance: 1250.5}).Handler().ServeHTTP(response, request)
+
+	// assert
+	_ = assert.Equal(t, http.StatusOK, response.Code)
+	_ = assert.JSONEq(t, `{"balance": 1250.5}`, response.Body.String())
+}
+
+func TestRestApiService_GetDaily(t *testing.T) {
+	// arrange
+	request := httptest.NewRequest(http.MethodGet, "/daily", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response := httptest.NewRecorder()
+
+	// act
+	newTestRestApiService(t, &fakeLedger{dailyOutcome: -45}).Handler().ServeHTTP(response, request)
+
+	// assert
+	_ = assert.Equal(t, http.StatusOK, response.Code)
+	_ = assert.JSONEq(t, `{"daily_outcome": -45}`, response.Body.String())
+}
+
+func TestRestApiService_GetDailyNotes(t *testing.T) {
+
+	_ = t.Run("notes of the day", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{notes: []string{"45.00 - mercado (@Ana)", "10.00 - pao"}}
+		request := httptest.NewRequest(http.MethodGet, "/daily/notes", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusOK, response.Code)
+		_ = assert.JSONEq(t, `{"notes": ["45.00 - mercado (@Ana)", "10.00 - pao"]}`, response.Body.String())
+	})
+
+	_ = t.Run("no notes", func(t *testing.T) {
+		// arrange
+		request := httptest.NewRequest(http.MethodGet, "/daily/notes", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, &fakeLedger{}).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusOK, response.Code)
+		_ = assert.JSONEq(t, `{"notes": []}`, response.Body.String())
+	})
+}
+
+func TestRestApiService_PostDailyZero(t *testing.T) {
+
+	_ = t.Run("zeroes the day", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{dailyOutcome: -80}
+		request := httptest.NewRequest(http.MethodPost, "/daily/zero", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusOK, response.Code)
+		_ = assert.Zero(t, ledger.dailyOutcome)
+	})
+
+	_ = t.Run("day with notes", func(t *testing.T) {
+		// arrange
+		ledger := &fakeLedger{dailyOutcome: -45, notes: []string{"45.00 - mercado"}}
+		request := httptest.NewRequest(http.MethodPost, "/daily/zero", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		response := httptest.NewRecorder()
+
+		// act
+		newTestRestApiService(t, ledger).Handler().ServeHTTP(response, request)
+
+		// assert
+		_ = assert.Equal(t, http.StatusConflict, response.Code)
+		_ = assert.Equal(t, -45.0, ledger.dailyOutcome)
+	})
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+const (
+	incomeDirection  = "income"
+	outcomeDirection = "outcome"
+	dayLayout        = "2006-01-02"
+)
+
+var _ Ledger = (*SQLiteLedgerService)(nil)
+
+type SQLiteLedgerService struct {
+	db  *sql.DB
+	now func() time.Time
+	// lastID is the row of the last recorded transaction, zero once undone, lastCents and lastDescription
+	// are what it was recorded with so an edited row is never undone
+	lastID          int64
+	lastCents       int64
+	lastDescription string
+	mutex           sync.Mutex
+}
+
+func NewSQLiteLedgerService(db *sql.DB) *SQLiteLedgerService {
+	return &SQLiteLedgerService{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+func (sls *SQLiteLedgerService) RecordTransaction(transaction *domain.Transaction) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	direction := outcomeDirection
+	if transaction.IsIncome() {
+		direction = incomeDirection
+	}
+
+	sls.mutex.Lock()
+	defer sls.mutex.Unlock()
+
+	now := sls.now()
+	result, err := sls.db.Exec(
+		`INSERT INTO transactions (day, amount, description, category, currency, original_amount, direction, author, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		transaction.Day(now).Format(dayLayout), toCents(transaction.Amount), transaction.Description, transaction.Category,
+		transaction.Currency, toCents(transaction.OriginalAmount), direction, transaction.Author, now.Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	sls.lastID, err = result.LastInsertId()
+	sls.lastCents = toCents(transaction.Amount)
+	sls.lastDescription = transaction.Description
+	return err
+}
+
+func (sls *SQLiteLedgerService) UndoLastTransaction() (*domain.Transaction, error) {
+	sls.mutex.Lock()
+	defer sls.mutex.Unlock()
+
+	if sls.lastID == 0 {
+		return nil, domain.ErrNothingToUndo
+	}
+
+	var cents, originalCents int64
+	var direction string
+	transaction := &domain.Transaction{}
+	err := sls.db.QueryRow(
+		`SELECT amount, description, category, currency, original_amount, direction, author FROM transactions WHERE id = ?`, sls.lastID,
+	).Scan(&cents, &transaction.Description, &transaction.Category, &transaction.Currency, &originalCents, &direction, &transaction.Author)
+	if errors.Is(err, sql.ErrNoRows) {
+		sls.lastID = 0
+		return nil, domain.ErrTransactionEdited
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cents != sls.lastCents || transaction.Description != sls.lastDescription {
+		sls.lastID = 0
+		return nil, domain.ErrTransactionEdited
+	}
+
+	transaction.Amount = fromCents(cents)
+	transaction.OriginalAmount = fromCents(originalCents)
+	if direction == outcomeDirection {
+		transaction.Amount = -transaction.Amount
+		transaction.OriginalAmount = -transaction.OriginalAmount
+	}
+
+	if _, err := sls.db.Exec(`DELETE FROM transactions WHERE id = ?`, sls.lastID); err != nil {
+		return nil, err
+	}
+
+	sls.lastID = 0
+	return transaction, nil
+}
+
+func (sls *SQLiteLedgerService) DailyOutcome() (float64, error) {
+	var cents int64
+	err := sls.db.QueryRow(
+		`SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE day = ? AND direction = ?`,
+		sls.today(), outcomeDirection,
+	).Scan(&cents)
+	if err != nil {
+		return 0, err
+	}
+
+	return fromCents(cents), nil
+}
+
+func (sls *SQLiteLedgerService) Balance() (float64, error) {
+	var cents int64
+	err := sls.db.QueryRow(
+		`SELECT COALESCE(SUM(CASE direction WHEN ? THEN amount ELSE -amount END), 0) FROM transactions WHERE day <= ?`,
+		incomeDirection, sls.today(),
+	).Scan(&cents)
+	if err != nil {
+		return 0, err
+	}
+
+	return fromCents(cents), nil
+}
+
+func (sls *SQLiteLedgerService) DailyNotes() ([]string, error) {
+	rows, err := sls.db.Query(
+		`SELECT amount, description, category, currency, original_amount, author FROM transactions WHERE day = ? AND direction = ? ORDER BY id`,
+		sls.today(), outcomeDirection,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []string
+	for rows.Next() {
+		var cents, originalCents int64
+		transaction := &domain.Transaction{}
+		if err := rows.Scan(&cents, &transaction.Description, &transaction.Category, &transaction.Currency, &originalCents, &transaction.Author); err != nil {
+			return nil, err
+		}
+		transaction.Amount = fromCents(cents)
+		transaction.OriginalAmount = fromCents(originalCents)
+		notes = append(notes, transaction.Note())
+	}
+
+	return notes, rows.Err()
+}
+
+func (sls *SQLiteLedgerService) RemoveDailyNote(index int) (*domain.Transaction, error) {
+	id, removed, err := sls.dailyOutcomeAt(index)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := sls.db.Exec(`DELETE FROM transactions WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+func (sls *SQLiteLedgerService) EditDailyNote(index int, transaction *domain.Transaction) (*domain.Transaction, error) {
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	id, replaced, err := sls.dailyOutcomeAt(index)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sls.db.Exec(
+		`UPDATE transactions SET amount = ?, description = ?, category = ?, currency = ?, original_amount = ?, author = ? WHERE id = ?`,
+		toCents(transaction.Amount), transaction.Description, transaction.Category, transaction.Currency, toCents(transaction.OriginalAmount),
+		transaction.Author, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// dailyOutcomeAt returns the id and the transaction of the one-based index of DailyNotes
+func (sls *SQLiteLedgerService) dailyOutcomeAt(index int) (int64, *domain.Transaction, error) {
+	if index < 1 {
+		return 0, nil, domain.ErrNoteNotFound
+	}
+
+	var id, cents, originalCents int64
+	transaction := &domain.Transaction{}
+	err := sls.db.QueryRow(
+		`SELECT id, amount, description, category, currency, original_amount, author FROM transactions
+			WHERE day = ? AND direction = ? ORDER BY id LIMIT 1 OFFSET ?`,
+		sls.today(), outcomeDirection, index-1,
+	).Scan(&id, &cents, &transaction.Description, &transaction.Category, &transaction.Currency, &originalCents, &transaction.Author)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, domain.ErrNoteNotFound
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	transaction.Amount = fromCents(cents)
+	transaction.OriginalAmount = fromCents(originalCents)
+	return id, transaction, nil
+}
+
+func (sls *SQLiteLedgerService) CategoryTotals() (map[string]float64, error) {
+	rows, err := sls.db.Query(
+		`SELECT category, SUM(amount) FROM transactions WHERE day LIKE ? AND direction = ? GROUP BY category`,
+		sls.now().Format("2006-01")+"-%", outcomeDirection,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var category string
+		var cents int64
+		if err := rows.Scan(&category, &cents); err != nil {
+			return nil, err
+		}
+		totals[category] = fromCents(cents)
+	}
+
+	return totals, rows.Err()
+}
+
+func (sls *SQLiteLedgerService) MonthSummary(month time.Time) (*domain.MonthSummary, error) {
+	days := domain.ElapsedDays(month, sls.now())
+	firstDay := month.Format(dayLayout)
+	lastDay := month.AddDate(0, 0, days-1).Format(dayLayout)
+
+	summary := &domain.MonthSummary{
+		Month:         month,
+		DailyOutcomes: make([]float64, days),
+	}
+
+	var incomeCents, balanceCents int64
+	err := sls.db.QueryRow(
+		`SELECT COALESCE(SUM(CASE WHEN direction = ?1 AND day >= ?2 THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE direction WHEN ?1 THEN amount ELSE -amount END), 0)
+			FROM transactions WHERE day <= ?3`,
+		incomeDirection, firstDay, lastDay,
+	).Scan(&incomeCents, &balanceCents)
+	if err != nil {
+		return nil, err
+	}
+	summary.Income = fromCents(incomeCents)
+	summary.ClosingBalance = fromCents(balanceCents)
+
+	rows, err := sls.db.Query(
+		`SELECT day, SUM(amount) FROM transactions WHERE day BETWEEN ? AND ? AND direction = ? GROUP BY day`,
+		firstDay, lastDay, outcomeDirection,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day string
+		var cents int64
+		if err := rows.Scan(&day, &cents); err != nil {
+			return nil, err
+		}
+		date, err := time.ParseInLocation(dayLayout, day, month.Location())
+		if err != nil {
+			return nil, err
+		}
+		summary.DailyOutcomes[date.Day()-1] = fromCents(cents)
+		summary.Outcome += fromCents(cents)
+	}
+
+	return summary, rows.Err()
+}
+
+func (sls *SQLiteLedgerService) Transactions(from, to time.Time) ([]*domain.Transaction, error) {
+	rows, err := sls.db.Query(
+		`SELECT day, amount, description, category, currency, original_amount, direction, author
+			FROM transactions WHERE day BETWEEN ? AND ? ORDER BY day, id`,
+		from.Format(dayLayout), to.Format(dayLayout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*domain.Transaction
+	for rows.Next() {
+		var day, direction string
+		var cents, originalCents int64
+		transaction := &domain.Transaction{}
+		err := rows.Scan(&day, &cents, &transaction.Description, &transaction.Category, &transaction.Currency,
+			&originalCents, &direction, &transaction.Author)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction.Date, err = time.ParseInLocation(dayLayout, day, from.Location())
+		if err != nil {
+			return nil, err
+		}
+		transaction.Amount = fromCents(cents)
+		transaction.OriginalAmount = fromCents(originalCents)
+		if direction == outcomeDirection {
+			transaction.Amount = -transaction.Amount
+			transaction.OriginalAmount = -transaction.OriginalAmount
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+func (sls *SQLiteLedgerService) ZeroDaily() error {
+	notes, err := sls.DailyNotes()
+	if err != nil {
+		return err
+	}
+
+	if len(notes) > 0 {
+		return domain.ErrDailyHasNotes
+	}
+
+	_, err = sls.db.Exec(`INSERT OR IGNORE INTO zeroed_days (day) VALUES (?)`, sls.today())
+	return err
+}
+
+func (sls *SQLiteLedgerService) NeedsDailyReminder() (bool, error) {
+	var logged bool
+	err := sls.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM transactions WHERE day = ?1 AND direction = ?2)
+			OR EXISTS (SELECT 1 FROM zeroed_days WHERE day = ?1)`,
+		sls.today(), outcomeDirection,
+	).Scan(&logged)
+	if err != nil {
+		return false, err
+	}
+
+	return !logged, nil
+}
+
+func (sls *SQLiteLedgerService) today() string {
+	return sls.now().Format(dayLayout)
+}
+
+func toCents(value float64) int64 {
+	return int64(math.Round(math.Abs(value) * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func newTestSQLiteLedger(t *testing.T, now time.Time) *SQLiteLedgerService {
+	t.Helper()
+
+	db, err := configuration.BuildSQLiteDB(context.Background(), ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ledger := NewSQLiteLedgerService(db)
+	ledger.now = func() time.Time { return now }
+	return ledger
+}
+
+func TestSQLiteLedgerService(t *testing.T) {
+	today := time.Date(2026, 3, 12, 10, 0, 0, 0, time.Local)
+
+	_ = t.Run("records transactions and computes totals", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today.AddDate(0, 0, -1))
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: 1000, Description: "salario"})
+		ledger.now = func() time.Time { return today }
+
+		// act
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -30.5, Description: "cerveja", Author: "Ana"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		dailyOutcome, _ := ledger.DailyOutcome()
+		balance, _ := ledger.Balance()
+		notes, _ := ledger.DailyNotes()
+
+		// assert
+		_ = assert.Equal(t, 40.5, dailyOutcome)
+		_ = assert.Equal(t, 959.5, balance)
+		_ = assert.Equal(t, []string{"30.50 - cerveja (@Ana)", "10.00 - pao"}, notes)
+	})
+
+	_ = t.Run("backdated transaction", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+
+		// act
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -20, Description: "padaria", Date: today.AddDate(0, 0, -1)})
+		dailyOutcome, _ := ledger.DailyOutcome()
+		balance, _ := ledger.Balance()
+
+		// assert
+		_ = assert.Zero(t, dailyOutcome)
+		_ = assert.Equal(t, -20.0, balance)
+	})
+
+	_ = t.Run("foreign currency note", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		transaction := &domain.Transaction{Amount: -15, Description: "taxi", Currency: "USD"}
+		transaction.Convert(5)
+
+		// act
+		_ = ledger.RecordTransaction(transaction)
+		dailyOutcome, _ := ledger.DailyOutcome()
+		notes, _ := ledger.DailyNotes()
+
+		// assert
+		_ = assert.Equal(t, 75.0, dailyOutcome)
+		_ = assert.Equal(t, []string{"75.00 - taxi (USD 15.00)"}, notes)
+	})
+
+	_ = t.Run("zero daily with notes", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+
+		// act
+		err := ledger.ZeroDaily()
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrDailyHasNotes)
+	})
+
+	_ = t.Run("daily reminder", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+
+		// act
+		beforeZero, _ := ledger.NeedsDailyReminder()
+		err := ledger.ZeroDaily()
+		afterZero, _ := ledger.NeedsDailyReminder()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.True(t, beforeZero)
+		_ = assert.False(t, afterZero)
+	})
+
+	_ = t.Run("remove and edit daily notes", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -25, Description: "uber"})
+
+		// act
+		replaced, errEdit := ledger.EditDailyNote(2, &domain.Transaction{Amount: -3, Description: "cafe", Category: "lanche"})
+		removed, errRemove := ledger.RemoveDailyNote(1)
+		_, errMissing := ledger.RemoveDailyNote(3)
+		dailyOutcome, _ := ledger.DailyOutcome()
+		notes, _ := ledger.DailyNotes()
+
+		// assert
+		_ = assert.NoError(t, errEdit)
+		_ = assert.NoError(t, errRemove)
+		_ = assert.Equal(t, 300.0, replaced.Amount)
+		_ = assert.Equal(t, "pao", removed.Description)
+		_ = assert.ErrorIs(t, errMissing, domain.ErrNoteNotFound)
+		_ = assert.Equal(t, 28.0, dailyOutcome)
+		_ = assert.Equal(t, []string{"3.00 - cafe #lanche", "25.00 - uber"}, notes)
+	})
+
+	_ = t.Run("undo last transaction", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe", Category: "lanche"})
+
+		// act
+		undone, err := ledger.UndoLastTransaction()
+		_, errAgain := ledger.UndoLastTransaction()
+		notes, _ := ledger.DailyNotes()
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, &domain.Transaction{Amount: -300, Description: "cafe", Category: "lanche"}, undone)
+		_ = assert.ErrorIs(t, errAgain, domain.ErrNothingToUndo)
+		_ = assert.Equal(t, []string{"10.00 - pao"}, notes)
+	})
+
+	_ = t.Run("undo an edited transaction", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -300, Description: "cafe"})
+		_, _ = ledger.EditDailyNote(2, &domain.Transaction{Amount: -3, Description: "cafe"})
+
+		// act
+		_, err := ledger.UndoLastTransaction()
+		notes, _ := ledger.DailyNotes()
+
+		// assert
+		_ = assert.ErrorIs(t, err, domain.ErrTransactionEdited)
+		_ = assert.Equal(t, []string{"10.00 - pao", "3.00 - cafe"}, notes)
+	})
+
+	_ = t.Run("month summary", func(t *testing.T) {
+		// arrange
+		ledger := newTestSQLiteLedger(t, today)
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: 500, Description: "saldo", Date: today.AddDate(0, -1, 0)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: 1000, Description: "salario", Date: today.AddDate(0, 0, -11)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -30, Description: "mercado", Date: today.AddDate(0, 0, -11)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -120, Description: "farmacia", Date: today.AddDate(0, 0, -2)})
+		_ = ledger.RecordTransaction(&domain.Transaction{Amount: -10, Description: "pao"})
+
+		// act
+		summary, err := ledger.MonthSummary(time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local))
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 1000.0, summary.Income)
+		_ = assert.Equal(t, 160.0, summary.Outcome)
+		_ = assert.Equal(t, 1340.0, summary.ClosingBalance)
+		_ = assert.Len(t, summary.DailyOutcomes, 12)
+		_ = assert.Equal(t, 30.0, summary.DailyOutcomes[0])
+		_ = assert.Equal(t, 120.0, summary.DailyOutcomes[9])
+	})
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"github.com/vitortenor/sheet-bot/internal/client"
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+type TelegramPollingService struct {
+	appConfig    *configuration.ApplicationConfig
+	client       *client.TelegramClient
+	offset       int64
+	allowedChats map[int64]bool
+	// pending are the updates of the last batch not acknowledged yet, in order,
+	// updates without a message to handle are kept so the offset moves past them too
+	pending []pendingUpdate
+}
+
+type pendingUpdate struct {
+	updateId int64
+	message  *domain.Message
+}
+
+var _ Acknowledger = (*TelegramPollingService)(nil)
+
+func NewTelegramPollingService(appConfig *configuration.ApplicationConfig, tc *client.TelegramClient) *TelegramPollingService {
+	allowedChats := make(map[int64]bool)
+	for _, chatId := range appConfig.Telegram.AllowedChatIds {
+		allowedChats[chatId] = true
+	}
+
+	return &TelegramPollingService{
+		appConfig:    appConfig,
+		client:       tc,
+		allowedChats: allowedChats,
+	}
+}
+
+func (tps *TelegramPollingService) Name() string {
+	return "telegram"
+}
+
+func (tps *TelegramPollingService) Open(_ context.Context) error {
+	if len(tps.allowedChats) == 0 {
+		log.Warn("no telegram chat is allowed, every message will be ignored")
+	}
+	log.Info("telegram polling started successfully")
+	return nil
+}
+
+func (tps *TelegramPollingService) Receive(ctx context.Context) ([]*domain.Message, error) {
+	updates, err := tps.client.GetUpdates(ctx, tps.offset, tps.pollTimeout())
+	if err != nil {
+		return nil, fmt.Errorf("error getting updates: %w", err)
+	}
+
+	// updates are only confirmed to telegram, by the offset, once handled; the unhandled ones come again
+	tps.pending = nil
+	var messages []*domain.Message
+	for _, update := range updates {
+		pending := pendingUpdate{updateId: update.UpdateId}
+		switch {
+		case update.Message == nil || update.Message.Text == "":
+		case !tps.allowedChats[update.Message.Chat.Id]:
+			log.Warnf("ignoring telegram message from chat %d: chat is not allowed", update.Message.Chat.Id)
+		default:
+			pending.message = tps.toDomainMessage(update.Message)
+			messages = append(messages, pending.message)
+		}
+		tps.pending = append(tps.pending, pending)
+	}
+	tps.advance()
+
+	return messages, nil
+}
+
+// Ack moves the offset past the message and the ignored updates that follow it
+func (tps *TelegramPollingService) Ack(message *domain.Message) {
+	for i, pending := range tps.pending {
+		if pending.message == message {
+			tps.offset = pending.updateId + 1
+			tps.pending = tps.pending[i+1:]
+			tps.advance()
+			return
+		}
+	}
+}
+
+// advance moves the offset past the leading updates that have no message to handle
+func (tps *TelegramPollingService) advance() {
+	for len(tps.pending) > 0 && tps.pending[0].message == nil {
+		tps.offset = tps.pending[0].updateId + 1
+		tps.pending = tps.pending[1:]
+	}
+}
+
+func (tps *TelegramPollingService) Reply(ctx context.Context, to *domain.Message, reply *domain.Message) error {
+	chatId, err := strconv.ParseInt(to.ChatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat id %q: %w", to.ChatID, err)
+	}
+
+	messageId, _ := strconv.ParseInt(to.ID, 10, 64)
+	return tps.client.SendMessage(ctx, chatId, reply.Message, messageId)
+}
+
+func (tps *TelegramPollingService) Send(ctx context.Context, chatID string, message *domain.Message) error {
+	chatId, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat id %q: %w", chatID, err)
+	}
+
+	return tps.client.SendMessage(ctx, chatId, message.Message, 0)
+}
+
+func (tps *TelegramPollingService) Chats() []string {
+	var chats []string
+	for _, chatId := range tps.appConfig.Telegram.AllowedChatIds {
+		chats = append(chats, strconv.FormatInt(chatId, 10))
+	}
+	return chats
+}
+
+func (tps *TelegramPollingService) Close() error {
+	return nil
+}
+
+func (tps *TelegramPollingService) pollTimeout() time.Duration {
+	return time.Duration(tps.appConfig.Telegram.PollTimeout) * time.Second
+}
+
+func (tps *TelegramPollingService) toDomainMessage(message *client.TelegramMessage) *domain.Message {
+	sender := ""
+	if message.From != nil {
+		sender = message.From.FirstName
+		if sender == "" {
+			sender = message.From.Username
+		}
+	}
+
+	return &domain.Message{
+		ID:        strconv.FormatInt(message.MessageId, 10),
+		ChatID:    strconv.FormatInt(message.Chat.Id, 10),
+		Sender:    sender,
+		Timestamp: time.Unix(message.Date, 0),
+		Message:   message.Text,
+	}
+}
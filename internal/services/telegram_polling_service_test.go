@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitortenor/sheet-bot/internal/client"
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+)
+
+// newTestTelegramPollingService answers every getUpdates with the same batch and records the offsets asked for
+func newTestTelegramPollingService(t *testing.T, updates string, offsets *[]float64) *TelegramPollingService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		offset, _ := body["offset"].(float64)
+		*offsets = append(*offsets, offset)
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + updates + `}`))
+	}))
+	t.Cleanup(server.Close)
+
+	appConfig := &configuration.ApplicationConfig{}
+	appConfig.Telegram.AllowedChatIds = []int64{42}
+	return NewTelegramPollingService(appConfig, client.NewTelegramClient(server.URL, "token", 0))
+}
+
+func TestTelegramPollingService_Receive(t *testing.T) {
+
+	_ = t.Run("offset only moves past handled updates", func(t *testing.T) {
+		// arrange
+		var offsets []float64
+		tps := newTestTelegramPollingService(t, `[
+			{"update_id":7,"message":{"message_id":1,"date":1700000000,"text":"-30 / cerveja","chat":{"id":42}}},
+			{"update_id":8,"message":{"message_id":2,"date":1700000000,"text":"oi","chat":{"id":99}}},
+			{"update_id":9,"message":{"message_id":3,"date":1700000000,"text":"-10 / pao","chat":{"id":42}}}
+		]`, &offsets)
+
+		// act
+		first, err := tps.Receive(context.Background())
+		require.NoError(t, err)
+		tps.Ack(first[0])
+		_, _ = tps.Receive(context.Background())
+
+		// assert
+		_ = assert.Len(t, first, 2)
+		_ = assert.Equal(t, []float64{0, 9}, offsets)
+	})
+
+	_ = t.Run("ignored updates at the start are confirmed at once", func(t *testing.T) {
+		// arrange
+		var offsets []float64
+		tps := newTestTelegramPollingService(t, `[
+			{"update_id":7,"message":{"message_id":1,"date":1700000000,"text":"oi","chat":{"id":99}}}
+		]`, &offsets)
+
+		// act
+		messages, err := tps.Receive(context.Background())
+		require.NoError(t, err)
+		_, _ = tps.Receive(context.Background())
+
+		// assert
+		_ = assert.Empty(t, messages)
+		_ = assert.Equal(t, []float64{0, 8}, offsets)
+	})
+}
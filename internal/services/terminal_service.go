@@ -0,0 +1,81 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+const terminalChat = "terminal"
+
+type TerminalService struct {
+	scanner *bufio.Scanner
+	writer  io.Writer
+	counter int
+}
+
+func NewTerminalService(reader io.Reader, writer io.Writer) *TerminalService {
+	return &TerminalService{
+		scanner: bufio.NewScanner(reader),
+		writer:  writer,
+	}
+}
+
+func (ts *TerminalService) Name() string {
+	return "terminal"
+}
+
+func (ts *TerminalService) Open(_ context.Context) error {
+	_, err := fmt.Fprintln(ts.writer, "sheet-bot repl, type a message and press enter (ctrl+d to quit)")
+	return err
+}
+
+func (ts *TerminalService) Receive(_ context.Context) ([]*domain.Message, error) {
+	for {
+		if _, err := fmt.Fprint(ts.writer, "> "); err != nil {
+			return nil, err
+		}
+
+		if !ts.scanner.Scan() {
+			if err := ts.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+
+		line := strings.TrimSpace(ts.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		ts.counter++
+		return []*domain.Message{{
+			ID:        fmt.Sprint(ts.counter),
+			ChatID:    terminalChat,
+			Timestamp: time.Now(),
+			Message:   line,
+		}}, nil
+	}
+}
+
+func (ts *TerminalService) Reply(ctx context.Context, to *domain.Message, reply *domain.Message) error {
+	return ts.Send(ctx, to.ChatID, reply)
+}
+
+func (ts *TerminalService) Send(_ context.Context, _ string, message *domain.Message) error {
+	_, err := fmt.Fprintln(ts.writer, message.Message)
+	return err
+}
+
+func (ts *TerminalService) Chats() []string {
+	return []string{terminalChat}
+}
+
+func (ts *TerminalService) Close() error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func TestTerminalService_Receive(t *testing.T) {
+
+	_ = t.Run("skips blank lines", func(t *testing.T) {
+		// arrange
+		var output strings.Builder
+		ts := NewTerminalService(strings.NewReader("\n   \n-10 / pao\n\nsaldo\n"), &output)
+
+		// act
+		first, errFirst := ts.Receive(context.Background())
+		second, errSecond := ts.Receive(context.Background())
+
+		// assert
+		_ = assert.NoError(t, errFirst)
+		_ = assert.NoError(t, errSecond)
+		_ = assert.Len(t, first, 1)
+		_ = assert.Equal(t, "-10 / pao", first[0].Message)
+		_ = assert.Equal(t, terminalChat, first[0].ChatID)
+		_ = assert.Len(t, second, 1)
+		_ = assert.Equal(t, "saldo", second[0].Message)
+		_ = assert.NotEqual(t, first[0].ID, second[0].ID)
+	})
+
+	_ = t.Run("end of input", func(t *testing.T) {
+		// arrange
+		var output strings.Builder
+		ts := NewTerminalService(strings.NewReader("saldo\n\n"), &output)
+		_, _ = ts.Receive(context.Background())
+
+		// act
+		messages, err := ts.Receive(context.Background())
+
+		// assert
+		_ = assert.ErrorIs(t, err, io.EOF)
+		_ = assert.Nil(t, messages)
+	})
+}
+
+func TestTerminalService_Reply(t *testing.T) {
+	// arrange
+	var output strings.Builder
+	ts := NewTerminalService(strings.NewReader("-10 / pao\nsaldo\n"), &output)
+	first, _ := ts.Receive(context.Background())
+	second, _ := ts.Receive(context.Background())
+
+	// act
+	errFirst := ts.Reply(context.Background(), first[0], &domain.Message{Message: "sys: processed -10 / pao"})
+	errSecond := ts.Reply(context.Background(), second[0], &domain.Message{Message: "sys: balance R$ 90,00"})
+
+	// assert
+	_ = assert.NoError(t, errFirst)
+	_ = assert.NoError(t, errSecond)
+	_ = assert.Equal(t, "> > sys: processed -10 / pao\nsys: balance R$ 90,00\n", output.String())
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+// Transport is a chat front-end the bot receives messages from and replies through.
+type Transport interface {
+	// Name identifies the transport in logs.
+	Name() string
+	// Open prepares the transport to receive messages.
+	Open(ctx context.Context) error
+	// Receive returns the inbound messages that arrived since the last call, oldest first.
+	Receive(ctx context.Context) ([]*domain.Message, error)
+	// Reply answers an inbound message in the chat it came from.
+	Reply(ctx context.Context, to *domain.Message, reply *domain.Message) error
+	// Send posts a message to a chat without an inbound message to answer.
+	Send(ctx context.Context, chatID string, message *domain.Message) error
+	// Chats returns the chats scheduled messages are posted to.
+	Chats() []string
+	Close() error
+}
+
+// FileSender is implemented by transports that can attach files to a chat.
+type FileSender interface {
+	// SendFile posts the file at path to a chat with the caption below it.
+	SendFile(ctx context.Context, chatID string, path string, caption string) error
+}
+
+// Acknowledger is implemented by transports that deliver again the messages not acknowledged.
+type Acknowledger interface {
+	// Ack marks a message received from the transport as handled.
+	Ack(message *domain.Message)
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/labstack/gommon/log"
@@ -14,57 +18,172 @@ import (
 )
 
 type WhatsAppCrawlerService struct {
-	context        context.Context
-	appConfig      *configuration.ApplicationConfig
-	messageService *MessageService
+	context   context.Context
+	appConfig *configuration.ApplicationConfig
+	browser   playwright.BrowserContext
+	page      playwright.Page
+	// groups are the watched chats, the page shows one of them at a time
+	groups      []configuration.Binding
+	currentChat string
+	chat        chatPage
+	// mutex keeps a chat open until the read or send done in it is finished,
+	// scheduled messages are sent while the bot is receiving
+	mutex sync.Mutex
 }
 
-func NewWhatsAppCrawlerService(ctx context.Context, appConfig *configuration.ApplicationConfig, ms *MessageService) *WhatsAppCrawlerService {
-	return &WhatsAppCrawlerService{
-		context:        ctx,
-		appConfig:      appConfig,
-		messageService: ms,
-	}
+// chatPage is the page work done in the open chat
+type chatPage interface {
+	openChat(groupName string) error
+	getMessages(groupName string) ([]*domain.Message, error)
+	typeAndSend(message string) error
+	attachAndSend(path string, caption string) error
 }
 
-const (
-	interval       = time.Second / 2 // Check for new messages every 0.5 seconds
-	reminderHour   = 20              // 20 PM
-	reminderMinute = 30              // 30 minutes
-)
+type playwrightChatPage struct {
+	wcs *WhatsAppCrawlerService
+}
+
+func (pcp playwrightChatPage) openChat(groupName string) error {
+	return pcp.wcs.openChat(pcp.wcs.page, groupName)
+}
+
+func (pcp playwrightChatPage) getMessages(groupName string) ([]*domain.Message, error) {
+	return pcp.wcs.getMessages(pcp.wcs.page, groupName)
+}
+
+func (pcp playwrightChatPage) typeAndSend(message string) error {
+	return pcp.wcs.typeAndSend(pcp.wcs.page, message)
+}
+
+func (pcp playwrightChatPage) attachAndSend(path string, caption string) error {
+	return pcp.wcs.attachAndSend(pcp.wcs.page, path, caption)
+}
+
+func NewWhatsAppCrawlerService(ctx context.Context, appConfig *configuration.ApplicationConfig) *WhatsAppCrawlerService {
+	wcs := &WhatsAppCrawlerService{
+		context:   ctx,
+		appConfig: appConfig,
+		groups:    appConfig.ChatBindings(),
+	}
+	wcs.chat = playwrightChatPage{wcs: wcs}
+	return wcs
+}
 
 var (
+	prePlainTextRegex = regexp.MustCompile(`^\[([^\]]+)\]\s*([^:]+):\s*$`)
+	// pt-BR and en-US WhatsApp Web locales
+	prePlainTextLayouts = []string{"15:04, 2/1/2006", "3:04 PM, 1/2/2006"}
+
 	playwrightTimeout = playwright.Float(3600000) // 1 hour timeout for playwright operations
 	playwrightOptions = playwright.PageWaitForSelectorOptions{
 		Timeout: playwrightTimeout,
 	}
+	// the media preview shows up quickly, a missing one means the upload failed
+	attachOptions = playwright.PageWaitForSelectorOptions{
+		Timeout: playwright.Float(30000),
+	}
 )
 
-func (wcs *WhatsAppCrawlerService) WhatsAppCrawler() {
+func (wcs *WhatsAppCrawlerService) Name() string {
+	return "whatsapp"
+}
+
+func (wcs *WhatsAppCrawlerService) Open(_ context.Context) error {
 	browser, err := wcs.launchBrowser()
 	if err != nil {
-		log.Fatalf("error launching browser: %v", err)
+		return fmt.Errorf("error launching browser: %w", err)
 	}
-	defer browser.Close()
+	wcs.browser = browser
 
 	page, err := wcs.openWhatsAppPage(browser)
 	if err != nil {
-		log.Fatalf("error opening WhatsApp page: %v", err)
+		return fmt.Errorf("error opening WhatsApp page: %w", err)
 	}
+	wcs.page = page
 
-	if wcs.appConfig.WhatsApp.IsArchived {
-		if err = wcs.openArchivedChats(page); err != nil {
-			log.Fatalf("error opening archived chats: %v", err)
+	wcs.mutex.Lock()
+	defer wcs.mutex.Unlock()
+	if err = wcs.chat.openChat(wcs.groups[0].GroupName); err != nil {
+		return err
+	}
+
+	log.Info("whatsApp crawler started successfully")
+	return nil
+}
+
+func (wcs *WhatsAppCrawlerService) Receive(_ context.Context) ([]*domain.Message, error) {
+	var pending []*domain.Message
+	for _, group := range wcs.groups {
+		messages, err := wcs.readChat(group.GroupName)
+		if err != nil {
+			return nil, err
 		}
+		pending = append(pending, wcs.pendingMessages(messages)...)
 	}
 
-	if err = wcs.openGroupChat(page); err != nil {
-		log.Fatalf("error opening group chat: %v", err)
+	return pending, nil
+}
+
+// readChat reads the group's messages before any send can switch the page to another chat
+func (wcs *WhatsAppCrawlerService) readChat(groupName string) ([]*domain.Message, error) {
+	wcs.mutex.Lock()
+	defer wcs.mutex.Unlock()
+
+	if err := wcs.chat.openChat(groupName); err != nil {
+		return nil, err
 	}
 
-	log.Info("whatsApp crawler started successfully")
-	wcs.scheduledDailyReminder(page)
-	wcs.checkMessages(page)
+	messages, err := wcs.chat.getMessages(groupName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (wcs *WhatsAppCrawlerService) Reply(_ context.Context, to *domain.Message, reply *domain.Message) error {
+	wcs.mutex.Lock()
+	defer wcs.mutex.Unlock()
+
+	if err := wcs.chat.openChat(to.ChatID); err != nil {
+		return err
+	}
+	return wcs.chat.typeAndSend(reply.Message)
+}
+
+func (wcs *WhatsAppCrawlerService) Send(_ context.Context, chatID string, message *domain.Message) error {
+	wcs.mutex.Lock()
+	defer wcs.mutex.Unlock()
+
+	if err := wcs.chat.openChat(chatID); err != nil {
+		return err
+	}
+	return wcs.chat.typeAndSend(message.Message)
+}
+
+// SendFile uploads the file through the attach menu's file input and sends it with the caption
+func (wcs *WhatsAppCrawlerService) SendFile(_ context.Context, chatID string, path string, caption string) error {
+	wcs.mutex.Lock()
+	defer wcs.mutex.Unlock()
+
+	if err := wcs.chat.openChat(chatID); err != nil {
+		return err
+	}
+	return wcs.chat.attachAndSend(path, caption)
+}
+
+func (wcs *WhatsAppCrawlerService) Chats() []string {
+	var chats []string
+	for _, group := range wcs.groups {
+		chats = append(chats, group.GroupName)
+	}
+	return chats
+}
+
+func (wcs *WhatsAppCrawlerService) Close() error {
+	if wcs.browser == nil {
+		return nil
+	}
+	return wcs.browser.Close()
 }
 
 func (wcs *WhatsAppCrawlerService) launchBrowser() (playwright.BrowserContext, error) {
@@ -101,8 +220,54 @@ func (wcs *WhatsAppCrawlerService) openWhatsAppPage(browser playwright.BrowserCo
 	return page, nil
 }
 
+// openChat shows the group on the page, archived groups are reached through the archived list
+func (wcs *WhatsAppCrawlerService) openChat(page playwright.Page, groupName string) error {
+	if wcs.currentChat == groupName {
+		return nil
+	}
+
+	isArchived := false
+	for _, group := range wcs.groups {
+		if group.GroupName == groupName {
+			isArchived = group.IsArchived
+		}
+	}
+
+	if isArchived {
+		if err := wcs.openArchivedChats(page); err != nil {
+			return fmt.Errorf("error opening archived chats: %w", err)
+		}
+	}
+
+	if err := wcs.openGroupChat(page, groupName); err != nil {
+		return fmt.Errorf("error opening group chat %s: %w", groupName, err)
+	}
+
+	// leave the archived list so the next group can be found in the main one
+	if isArchived && len(wcs.groups) > 1 {
+		if err := wcs.closeArchivedChats(page); err != nil {
+			return fmt.Errorf("error closing archived chats: %w", err)
+		}
+	}
+
+	wcs.currentChat = groupName
+	return nil
+}
+
+func (wcs *WhatsAppCrawlerService) closeArchivedChats(page playwright.Page) error {
+	backButton, err := page.QuerySelector(`span[data-icon="back"]`)
+	if err != nil {
+		return err
+	}
+	if backButton == nil {
+		return nil
+	}
+
+	return backButton.Click()
+}
+
 func (wcs *WhatsAppCrawlerService) openArchivedChats(page playwright.Page) error {
-	_, err := page.WaitForSelector(fmt.Sprintf("text='Arquivadas'"), playwrightOptions)
+	_, err := page.WaitForSelector("text='Arquivadas'", playwrightOptions)
 	if err != nil {
 		return err
 	}
@@ -119,13 +284,13 @@ func (wcs *WhatsAppCrawlerService) openArchivedChats(page playwright.Page) error
 	return nil
 }
 
-func (wcs *WhatsAppCrawlerService) openGroupChat(page playwright.Page) error {
-	_, err := page.WaitForSelector(fmt.Sprintf("text='%s'", wcs.appConfig.WhatsApp.GroupName), playwrightOptions)
+func (wcs *WhatsAppCrawlerService) openGroupChat(page playwright.Page, groupName string) error {
+	_, err := page.WaitForSelector(fmt.Sprintf("text='%s'", groupName), playwrightOptions)
 	if err != nil {
 		return err
 	}
 
-	sheetBot, err := page.QuerySelector(fmt.Sprintf(`span[title="%s"]`, wcs.appConfig.WhatsApp.GroupName))
+	sheetBot, err := page.QuerySelector(fmt.Sprintf(`span[title="%s"]`, groupName))
 	if err != nil {
 		return err
 	}
@@ -138,88 +303,96 @@ func (wcs *WhatsAppCrawlerService) openGroupChat(page playwright.Page) error {
 	return err
 }
 
-func (wcs *WhatsAppCrawlerService) checkMessages(page playwright.Page) {
-	log.Info("starting to check messages...")
-	for {
-		if err := wcs.handleMessages(page); err != nil {
-			log.Printf("error handling messages: %v", err)
-		}
-		time.Sleep(interval)
+// pendingMessages returns the messages sent after the last system message, oldest first.
+func (wcs *WhatsAppCrawlerService) pendingMessages(messages []*domain.Message) []*domain.Message {
+	messagesSize := len(messages)
+	if messagesSize == 0 {
+		return nil
 	}
-}
 
-func (wcs *WhatsAppCrawlerService) handleMessages(page playwright.Page) error {
-	messagesText, err := wcs.getMessagesText(page)
-	if err != nil {
-		return fmt.Errorf("error getting messages text: %w", err)
+	if wcs.checkIfIsSystemMessage(messages[messagesSize-1].Message) || strings.TrimSpace(messages[messagesSize-1].Message) == "" {
+		return nil
 	}
 
-	if err := wcs.processMessages(page, messagesText); err != nil {
-		return fmt.Errorf("error processing messages: %w", err)
+	first := messagesSize
+	for first > 0 && !wcs.checkIfIsSystemMessage(messages[first-1].Message) {
+		first--
 	}
 
-	return nil
+	return messages[first:]
 }
 
-func (wcs *WhatsAppCrawlerService) processMessages(page playwright.Page, messagesText []string) error {
-	messageTextSize := len(messagesText)
-	if messageTextSize == 0 {
-		return nil
+func (wcs *WhatsAppCrawlerService) getMessages(page playwright.Page, groupName string) ([]*domain.Message, error) {
+	mainDiv, err := page.QuerySelector(`div[id*="main"]`)
+	if err != nil {
+		return nil, err
 	}
 
-	if !wcs.checkIfIsSystemMessage(messagesText[messageTextSize-1]) && strings.TrimSpace(messagesText[messageTextSize-1]) != "" {
-		log.Info("processing messages...")
-		var messagesToSave []string
-		counter := 1
+	// every message bubble carries its author and time, e.g. data-pre-plain-text="[20:15, 12/03/2026] Ana: "
+	bubbles, err := mainDiv.QuerySelectorAll("div.copyable-text[data-pre-plain-text]")
+	if err != nil {
+		return nil, err
+	}
 
-		for messageTextSize-counter >= 0 && !wcs.checkIfIsSystemMessage(messagesText[messageTextSize-counter]) {
-			messagesToSave = append(messagesToSave, messagesText[messageTextSize-counter])
-			counter++
+	var messages []*domain.Message
+	for _, bubble := range bubbles {
+		prePlainText, err := bubble.GetAttribute("data-pre-plain-text")
+		if err != nil {
+			return nil, err
 		}
 
-		for _, message := range messagesToSave {
-			domainMessage := &domain.Message{
-				Message: message,
-			}
-
-			log.Info("processing message: ", domainMessage.Message)
-			response := wcs.messageService.ProcessAndReply(domainMessage)
-			err := wcs.typeAndSend(page, response.Message)
-			log.Info("message processed: ", response.Message)
-
-			if err != nil {
-				return err
-			}
+		messageText, err := wcs.getMessageText(bubble)
+		if err != nil {
+			return nil, err
 		}
-		log.Info("messages processed")
-	}
-	return nil
-}
 
-func (wcs *WhatsAppCrawlerService) getMessagesText(page playwright.Page) ([]string, error) {
-	mainDiv, err := page.QuerySelector(`div[id*="main"]`)
-	if err != nil {
-		return nil, err
+		sender, timestamp := parsePrePlainText(prePlainText, time.Now())
+		messages = append(messages, &domain.Message{
+			ChatID:    groupName,
+			Sender:    sender,
+			Timestamp: timestamp,
+			Message:   messageText,
+		})
 	}
+	return messages, nil
+}
 
-	children, err := mainDiv.QuerySelectorAll(".selectable-text.copyable-text span")
+func (wcs *WhatsAppCrawlerService) getMessageText(bubble playwright.ElementHandle) (string, error) {
+	children, err := bubble.QuerySelectorAll(".selectable-text.copyable-text span")
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	var messagesText []string
+	var messageText strings.Builder
 	for _, child := range children {
 		hasLexicalAttr, err := child.GetAttribute("data-lexical-text")
 		if err == nil && hasLexicalAttr == "true" {
 			continue
 		}
-		messageText, err := child.TextContent()
+		text, err := child.TextContent()
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-		messagesText = append(messagesText, messageText)
+		messageText.WriteString(text)
 	}
-	return messagesText, nil
+	return messageText.String(), nil
+}
+
+// parsePrePlainText reads the author and time of a "[20:15, 12/03/2026] Ana: " attribute,
+// falling back to now when the time is written in an unknown locale
+func parsePrePlainText(prePlainText string, now time.Time) (string, time.Time) {
+	matches := prePlainTextRegex.FindStringSubmatch(prePlainText)
+	if matches == nil {
+		return "", now
+	}
+
+	sender := strings.TrimSpace(matches[2])
+	for _, layout := range prePlainTextLayouts {
+		if timestamp, err := time.ParseInLocation(layout, matches[1], now.Location()); err == nil {
+			return sender, timestamp
+		}
+	}
+	return sender, now
 }
 
 func (wcs *WhatsAppCrawlerService) typeAndSend(page playwright.Page, message string) error {
@@ -233,33 +406,38 @@ func (wcs *WhatsAppCrawlerService) typeAndSend(page playwright.Page, message str
 	return page.Keyboard().Press("Enter")
 }
 
-func (wcs *WhatsAppCrawlerService) checkIfIsSystemMessage(message string) bool {
-	return strings.HasPrefix(message, domain.SystemMessagePrefix)
+func (wcs *WhatsAppCrawlerService) attachAndSend(page playwright.Page, path string, caption string) error {
+	attachButton, err := page.QuerySelector(`span[data-icon="plus-rounded"], span[data-icon="plus"], span[data-icon="attach-menu-plus"]`)
+	if err != nil {
+		return err
+	}
+	if attachButton == nil {
+		return errors.New("attach button not found")
+	}
+	if err := attachButton.Click(); err != nil {
+		return err
+	}
+
+	// the photos and documents entries of the menu are backed by hidden file inputs
+	fileInput := `input[type="file"][accept="*"]`
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		fileInput = `input[type="file"][accept*="image"]`
+	}
+	if err := page.SetInputFiles(fileInput, path); err != nil {
+		return err
+	}
+
+	// the media preview opens with the caption box focused
+	if _, err := page.WaitForSelector(`span[data-icon="send"]`, attachOptions); err != nil {
+		return err
+	}
+	// the caption keeps the system prefix so the image is never read back as a pending message
+	if err := page.Keyboard().Type(caption); err != nil {
+		return err
+	}
+	return page.Keyboard().Press("Enter")
 }
 
-func (wcs *WhatsAppCrawlerService) scheduledDailyReminder(page playwright.Page) {
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Error("panic recovered in scheduledDailyReminder")
-			}
-		}()
-
-		for {
-			now := time.Now()
-			nextReminder := time.Date(now.Year(), now.Month(), now.Day(), reminderHour, reminderMinute, 0, 0, now.Location())
-			if now.After(nextReminder) {
-				nextReminder = nextReminder.Add(24 * time.Hour)
-			}
-			time.Sleep(time.Until(nextReminder))
-
-			reminderMessage := wcs.messageService.sheetService.GetDailyReminder()
-			if reminderMessage != "" {
-				log.Info("sending daily reminder...")
-				if err := wcs.typeAndSend(page, reminderMessage); err != nil {
-					log.Error("error sending daily reminder: %v", err)
-				}
-			}
-		}
-	}()
+func (wcs *WhatsAppCrawlerService) checkIfIsSystemMessage(message string) bool {
+	return strings.HasPrefix(message, domain.SystemMessagePrefix)
 }
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
+)
+
+func TestParsePrePlainText(t *testing.T) {
+	now := time.Date(2026, 3, 12, 21, 0, 0, 0, time.Local)
+
+	_ = t.Run("pt-br locale", func(t *testing.T) {
+		// act
+		sender, timestamp := parsePrePlainText("[20:15, 12/03/2026] Ana: ", now)
+
+		// assert
+		_ = assert.Equal(t, "Ana", sender)
+		_ = assert.Equal(t, time.Date(2026, 3, 12, 20, 15, 0, 0, time.Local), timestamp)
+	})
+
+	_ = t.Run("en-us locale", func(t *testing.T) {
+		// act
+		sender, timestamp := parsePrePlainText("[8:15 PM, 3/12/2026] +55 11 91234-5678: ", now)
+
+		// assert
+		_ = assert.Equal(t, "+55 11 91234-5678", sender)
+		_ = assert.Equal(t, time.Date(2026, 3, 12, 20, 15, 0, 0, time.Local), timestamp)
+	})
+
+	_ = t.Run("unknown format", func(t *testing.T) {
+		// act
+		sender, timestamp := parsePrePlainText("Ana", now)
+
+		// assert
+		_ = assert.Empty(t, sender)
+		_ = assert.Equal(t, now, timestamp)
+	})
+}
+
+func TestWhatsAppCrawlerService_PendingMessages(t *testing.T) {
+
+	_ = t.Run("messages after the last system message", func(t *testing.T) {
+		// arrange
+		wcs := NewWhatsAppCrawlerService(context.Background(), &configuration.ApplicationConfig{})
+		messages := []*domain.Message{
+			{Sender: "Ana", Message: "-10 / pao"},
+			{Sender: "Bot", Message: "sys: processed -10 / pao"},
+			{Sender: "Ana", Message: "-45 / mercado"},
+			{Sender: "Rui", Message: "saldo"},
+		}
+
+		// act
+		pending := wcs.pendingMessages(messages)
+
+		// assert
+		_ = assert.Equal(t, messages[2:], pending)
+	})
+
+	_ = t.Run("already answered", func(t *testing.T) {
+		// arrange
+		wcs := NewWhatsAppCrawlerService(context.Background(), &configuration.ApplicationConfig{})
+		messages := []*domain.Message{
+			{Sender: "Ana", Message: "-10 / pao"},
+			{Sender: "Bot", Message: "sys: processed -10 / pao"},
+		}
+
+		// act
+		pending := wcs.pendingMessages(messages)
+
+		// assert
+		_ = assert.Nil(t, pending)
+	})
+}
+
+// fakeChatPage reads the messages of whatever chat is open, like the real page does
+type fakeChatPage struct {
+	mutex    sync.Mutex
+	current  string
+	blocking string
+	reading  chan struct{}
+	release  chan struct{}
+	messages map[string][]string
+	sent     map[string][]string
+}
+
+func (fcp *fakeChatPage) openChat(groupName string) error {
+	fcp.mutex.Lock()
+	defer fcp.mutex.Unlock()
+	fcp.current = groupName
+	return nil
+}
+
+func (fcp *fakeChatPage) getMessages(groupName string) ([]*domain.Message, error) {
+	// the read of the blocking chat holds until the test has tried to send
+	if groupName == fcp.blocking {
+		close(fcp.reading)
+		<-fcp.release
+	}
+
+	fcp.mutex.Lock()
+	defer fcp.mutex.Unlock()
+	var messages []*domain.Message
+	for _, message := range fcp.messages[fcp.current] {
+		messages = append(messages, &domain.Message{ChatID: fcp.current, Message: message})
+	}
+	return messages, nil
+}
+
+func (fcp *fakeChatPage) typeAndSend(message string) error {
+	fcp.mutex.Lock()
+	defer fcp.mutex.Unlock()
+	fcp.sent[fcp.current] = append(fcp.sent[fcp.current], message)
+	return nil
+}
+
+func (fcp *fakeChatPage) attachAndSend(_ string, caption string) error {
+	return fcp.typeAndSend(caption)
+}
+
+func TestWhatsAppCrawlerService_Receive(t *testing.T) {
+
+	_ = t.Run("a scheduled send waits for the chat being read", func(t *testing.T) {
+		// arrange
+		page := &fakeChatPage{
+			blocking: "casa",
+			reading:  make(chan struct{}),
+			release:  make(chan struct{}),
+			messages: map[string][]string{"casa": {"-10 / pao"}, "viagem": {"saldo"}},
+			sent:     map[string][]string{},
+		}
+		wcs := NewWhatsAppCrawlerService(context.Background(), &configuration.ApplicationConfig{})
+		wcs.groups = []configuration.Binding{{GroupName: "casa"}, {GroupName: "viagem"}}
+		wcs.chat = page
+
+		var pending []*domain.Message
+		received := make(chan error)
+		go func() {
+			var err error
+			pending, err = wcs.Receive(context.Background())
+			received <- err
+		}()
+		<-page.reading
+
+		// act
+		sent := make(chan error)
+		go func() {
+			sent <- wcs.Send(context.Background(), "viagem", &domain.Message{Message: "sys: lembrete"})
+		}()
+		select {
+		case err := <-sent:
+			t.Fatalf("send ran in the middle of a receive: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(page.release)
+
+		// assert
+		_ = assert.NoError(t, <-received)
+		_ = assert.NoError(t, <-sent)
+		_ = assert.Len(t, pending, 2)
+		_ = assert.Equal(t, "casa", pending[0].ChatID)
+		_ = assert.Equal(t, "-10 / pao", pending[0].Message)
+		_ = assert.Equal(t, []string{"sys: lembrete"}, page.sent["viagem"])
+	})
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	chartHeight    = 480
+	barChartWidth  = 640
+	pieChartWidth  = 400
+	chartMargin    = 40
+	pieRadius      = 110
+	legendLineSize = 18
+	// categories past this many are grouped in a single slice
+	maxPieSlices = 6
+)
+
+var (
+	chartBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	chartForeground = color.RGBA{R: 33, G: 33, B: 33, A: 255}
+	chartGrid       = color.RGBA{R: 224, G: 224, B: 224, A: 255}
+	chartBar        = color.RGBA{R: 37, G: 211, B: 102, A: 255}
+	chartPalette    = []color.RGBA{
+		{R: 66, G: 133, B: 244, A: 255},
+		{R: 219, G: 68, B: 55, A: 255},
+		{R: 244, G: 180, B: 0, A: 255},
+		{R: 15, G: 157, B: 88, A: 255},
+		{R: 171, G: 71, B: 188, A: 255},
+		{R: 0, G: 172, B: 193, A: 255},
+		{R: 158, G: 158, B: 158, A: 255},
+	}
+)
+
+// ChartSlice is one category of the pie chart
+type ChartSlice struct {
+	Label string
+	Value float64
+}
+
+// RenderSpendingChart draws the daily outcomes as bars, index 0 being the first day of the month,
+// with a category pie on the right side when slices are given, and encodes it as a PNG
+func RenderSpendingChart(title string, dailyOutcomes []float64, slices []ChartSlice) ([]byte, error) {
+	width := barChartWidth
+	slices = groupSmallSlices(slices)
+	if len(slices) > 0 {
+		width += pieChartWidth
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+	drawText(img, chartMargin, chartMargin/2+5, title)
+
+	drawBars(img, image.Rect(chartMargin, chartMargin, barChartWidth-chartMargin/2, chartHeight-chartMargin), dailyOutcomes)
+	if len(slices) > 0 {
+		drawPie(img, image.Pt(barChartWidth+pieChartWidth/2, chartMargin+pieRadius+10), slices)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawBars(img *image.RGBA, area image.Rectangle, values []float64) {
+	highest := 0.0
+	for _, value := range values {
+		highest = math.Max(highest, value)
+	}
+
+	// horizontal grid lines at every quarter of the highest value
+	for i := 0; i <= 4; i++ {
+		y := area.Max.Y - area.Dy()*i/4
+		draw.Draw(img, image.Rect(area.Min.X, y, area.Max.X, y+1), &image.Uniform{C: chartGrid}, image.Point{}, draw.Src)
+	}
+	if highest > 0 {
+		drawText(img, area.Min.X, area.Min.Y-4, FormatMoney(highest))
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	slot := area.Dx() / len(values)
+	for i, value := range values {
+		x := area.Min.X + i*slot
+		if highest > 0 && value > 0 {
+			height := int(math.Round(value / highest * float64(area.Dy())))
+			bar := image.Rect(x+1, area.Max.Y-height, x+max(slot-1, 2), area.Max.Y)
+			draw.Draw(img, bar, &image.Uniform{C: chartBar}, image.Point{}, draw.Src)
+		}
+		// label the first day and every fifth one
+		if day := i + 1; day == 1 || day%5 == 0 {
+			drawText(img, x, area.Max.Y+16, fmt.Sprint(day))
+		}
+	}
+}
+
+func drawPie(img *image.RGBA, center image.Point, slices []ChartSlice) {
+	total := 0.0
+	for _, slice := range slices {
+		total += slice.Value
+	}
+
+	// the end angle of each slice, clockwise from the top
+	ends := make([]float64, len(slices))
+	accumulated := 0.0
+	for i, slice := range slices {
+		accumulated += slice.Value
+		ends[i] = accumulated / total * 2 * math.Pi
+	}
+
+	for y := -pieRadius; y <= pieRadius; y++ {
+		for x := -pieRadius; x <= pieRadius; x++ {
+			if x*x+y*y > pieRadius*pieRadius {
+				continue
+			}
+			angle := math.Atan2(float64(x), float64(-y))
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			i := sort.SearchFloat64s(ends, angle)
+			img.Set(center.X+x, center.Y+y, chartPalette[min(i, len(slices)-1)%len(chartPalette)])
+		}
+	}
+
+	legendX := center.X - pieRadius
+	legendY := center.Y + pieRadius + 2*legendLineSize
+	for i, slice := range slices {
+		y := legendY + i*legendLineSize
+		swatch := image.Rect(legendX, y-10, legendX+10, y)
+		draw.Draw(img, swatch, &image.Uniform{C: chartPalette[i%len(chartPalette)]}, image.Point{}, draw.Src)
+		drawText(img, legendX+16, y, fmt.Sprintf("%s %s (%.0f%%)", slice.Label, FormatMoney(slice.Value), slice.Value/total*100))
+	}
+}
+
+// groupSmallSlices keeps the biggest positive slices and sums the rest as "outros"
+func groupSmallSlices(slices []ChartSlice) []ChartSlice {
+	var positive []ChartSlice
+	for _, slice := range slices {
+		if slice.Value > 0 {
+			positive = append(positive, slice)
+		}
+	}
+	sort.SliceStable(positive, func(i, j int) bool { return positive[i].Value > positive[j].Value })
+	if len(positive) <= maxPieSlices {
+		return positive
+	}
+
+	others := ChartSlice{Label: "outros"}
+	for _, slice := range positive[maxPieSlices-1:] {
+		others.Value += slice.Value
+	}
+	return append(positive[:maxPieSlices-1], others)
+}
+
+func drawText(img *image.RGBA, x, y int, text string) {
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{C: chartForeground},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
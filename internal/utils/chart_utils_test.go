@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSpendingChart(t *testing.T) {
+
+	_ = t.Run("daily bars only", func(t *testing.T) {
+		// arrange
+		dailyOutcomes := []float64{10, 0, 50, 25}
+
+		// act
+		chart, err := RenderSpendingChart("gastos diarios 03/2026", dailyOutcomes, nil)
+
+		// assert
+		_ = assert.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(chart))
+		require.NoError(t, err)
+		_ = assert.Equal(t, barChartWidth, img.Bounds().Dx())
+		_ = assert.Equal(t, chartHeight, img.Bounds().Dy())
+	})
+
+	_ = t.Run("category pie on the side", func(t *testing.T) {
+		// arrange
+		slices := []ChartSlice{{Label: "mercado", Value: 300}, {Label: "lazer", Value: 100}, {Label: "estorno", Value: -20}}
+
+		// act
+		chart, err := RenderSpendingChart("gastos diarios 03/2026", []float64{10}, slices)
+
+		// assert
+		_ = assert.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(chart))
+		require.NoError(t, err)
+		_ = assert.Equal(t, barChartWidth+pieChartWidth, img.Bounds().Dx())
+	})
+}
+
+func TestGroupSmallSlices(t *testing.T) {
+
+	_ = t.Run("groups the smallest categories", func(t *testing.T) {
+		// arrange
+		slices := []ChartSlice{
+			{Label: "a", Value: 1}, {Label: "b", Value: 7}, {Label: "c", Value: 6}, {Label: "d", Value: 5},
+			{Label: "e", Value: 4}, {Label: "f", Value: 3}, {Label: "g", Value: 2}, {Label: "h", Value: 0},
+		}
+
+		// act
+		grouped := groupSmallSlices(slices)
+
+		// assert
+		_ = assert.Len(t, grouped, maxPieSlices)
+		_ = assert.Equal(t, ChartSlice{Label: "b", Value: 7}, grouped[0])
+		_ = assert.Equal(t, ChartSlice{Label: "outros", Value: 3}, grouped[maxPieSlices-1])
+	})
+}
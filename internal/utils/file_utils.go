@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"os"
+)
+
+// WriteFileAtomically writes to a temporary file first so a crash never leaves a truncated file behind
+func WriteFileAtomically(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -2,16 +2,21 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
 	"google.golang.org/api/sheets/v4"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+	"github.com/vitortenor/sheet-bot/internal/domain"
 )
 
 const (
-	ColumnPerMonth   = 6
-	RowColumnPattern = "%s!%s%d"
+	RowColumnPattern      = "'%s'!%s%d"
+	RowColumnRangePattern = "'%s'!%s%d:%s%d"
+	CategoryRowsLimit     = 200
 )
 
 func CleanMoneyValue(value string) string {
@@ -22,6 +27,33 @@ func CleanMoneyValue(value string) string {
 	return strings.TrimSpace(newValue)
 }
 
+func ParseMoneyValue(value string) (float64, error) {
+	// sheets may render negative values as "-R$ 10,00", leaving a space between sign and number
+	cleanValue := strings.Join(strings.Fields(CleanMoneyValue(value)), "")
+	if cleanValue == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(cleanValue, 64)
+}
+
+func FormatMoney(value float64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = math.Abs(value)
+	}
+
+	integerPart, decimalPart, _ := strings.Cut(fmt.Sprintf("%.2f", value), ".")
+	var grouped []string
+	for len(integerPart) > 3 {
+		grouped = append([]string{integerPart[len(integerPart)-3:]}, grouped...)
+		integerPart = integerPart[:len(integerPart)-3]
+	}
+	grouped = append([]string{integerPart}, grouped...)
+
+	return fmt.Sprintf("%sR$ %s,%s", sign, strings.Join(grouped, "."), decimalPart)
+}
+
 func convertToXlsxColumn(columnNumber int) string {
 	title := ""
 	for columnNumber > 0 {
@@ -32,6 +64,14 @@ func convertToXlsxColumn(columnNumber int) string {
 	return title
 }
 
+func convertFromXlsxColumn(title string) int {
+	columnNumber := 0
+	for _, letter := range title {
+		columnNumber = columnNumber*26 + int(letter-'A'+1)
+	}
+	return columnNumber
+}
+
 func BuildNoteRequest(concatenatedNote string, sheetId int64, row, column int) *sheets.BatchUpdateSpreadsheetRequest {
 	return &sheets.BatchUpdateSpreadsheetRequest{
 		Requests: []*sheets.Request{
@@ -63,56 +103,91 @@ func BuildNoteRequest(concatenatedNote string, sheetId int64, row, column int) *
 /* range methods */
 
 // saldo
-func BuildBalanceRange() string {
-	column := convertToXlsxColumn(GetCurrentBalanceColumnNumber())
-	return buildRowColumnPattern(strconv.Itoa(GetCurrentYear()), column)
+func BuildBalanceRange(layout configuration.Layout, date time.Time) string {
+	return buildRowColumnPattern(layout, date, GetBalanceColumnNumber(layout, date))
 }
 
 // diario
-func BuildDailyOutcomeRange() string {
-	column := convertToXlsxColumn(GetCurrentDailyOutcomeColumnNumber() + 1)
-	return buildRowColumnPattern(strconv.Itoa(GetCurrentYear()), column)
+func BuildDailyOutcomeRange(layout configuration.Layout, date time.Time) string {
+	return buildRowColumnPattern(layout, date, GetDailyOutcomeColumnNumber(layout, date))
 }
 
 // entrada
-func BuildIncomeRange() string {
-	column := convertToXlsxColumn(GetCurrentIncomeColumnNumber() + 1)
-	return buildRowColumnPattern(strconv.Itoa(GetCurrentYear()), column)
+func BuildIncomeRange(layout configuration.Layout, date time.Time) string {
+	return buildRowColumnPattern(layout, date, GetIncomeColumnNumber(layout, date))
 }
 
-func buildRowColumnPattern(sheetName string, column string) string {
-	return fmt.Sprintf(RowColumnPattern, sheetName, column, GetCurrentDayRow())
+// diario column for every day of the date's month
+func BuildMonthDailyOutcomeRange(layout configuration.Layout, date time.Time) string {
+	return buildMonthColumnRange(layout, date, GetDailyOutcomeColumnNumber(layout, date))
+}
+
+// entrada column for every day of the date's month
+func BuildMonthIncomeRange(layout configuration.Layout, date time.Time) string {
+	return buildMonthColumnRange(layout, date, GetIncomeColumnNumber(layout, date))
+}
+
+// saldo column for every day of the date's month
+func BuildMonthBalanceRange(layout configuration.Layout, date time.Time) string {
+	return buildMonthColumnRange(layout, date, GetBalanceColumnNumber(layout, date))
+}
+
+// category names column of the category tab
+func BuildCategoryNamesRange(layout configuration.Layout, date time.Time) string {
+	return fmt.Sprintf(RowColumnRangePattern, GetCategorySheetName(layout, date), "A", 1, "A", CategoryRowsLimit)
+}
+
+// category total of the date's month, months are laid out from column B (january) to M (december)
+func BuildCategoryRange(layout configuration.Layout, date time.Time, row int) string {
+	column := convertToXlsxColumn(int(date.Month()) + 1)
+	return fmt.Sprintf(RowColumnPattern, GetCategorySheetName(layout, date), column, row)
+}
+
+func buildMonthColumnRange(layout configuration.Layout, date time.Time, columnNumber int) string {
+	column := convertToXlsxColumn(columnNumber + 1)
+	firstRow := layout.HeaderRows + 1
+	// the rows after the last day of a shorter month are not days
+	lastRow := layout.HeaderRows + domain.DaysInMonth(date)
+	return fmt.Sprintf(RowColumnRangePattern, GetSheetName(layout, date), column, firstRow, column, lastRow)
+}
+
+func buildRowColumnPattern(layout configuration.Layout, date time.Time, columnNumber int) string {
+	// column numbers are zero-based while the A1 notation is one-based
+	column := convertToXlsxColumn(columnNumber + 1)
+	return fmt.Sprintf(RowColumnPattern, GetSheetName(layout, date), column, GetDayRow(layout, date))
 }
 
 /* row and column methods */
 
-func GetCurrentIncomeColumnNumber() int {
-	// 5 is the difference between the end of month range and the income 'entrada' column
-	return getCurrentMonthColumn() - 5
+func GetIncomeColumnNumber(layout configuration.Layout, date time.Time) int {
+	return getMonthColumn(layout, date) + layout.IncomeOffset
 }
 
-func GetCurrentDailyOutcomeColumnNumber() int {
-	// 3 is the difference between the end of month range and the outcome 'diario' column
-	return getCurrentMonthColumn() - 3
+func GetDailyOutcomeColumnNumber(layout configuration.Layout, date time.Time) int {
+	return getMonthColumn(layout, date) + layout.DailyOutcomeOffset
 }
 
-func GetCurrentBalanceColumnNumber() int {
-	// 1 is the difference between the end of month range and the balance 'saldo' column
-	return getCurrentMonthColumn() - 1
+func GetBalanceColumnNumber(layout configuration.Layout, date time.Time) int {
+	return getMonthColumn(layout, date) + layout.BalanceOffset
 }
 
 /* date methods */
 
-func GetCurrentYear() int {
-	return time.Now().Year()
+func GetSheetName(layout configuration.Layout, date time.Time) string {
+	return strings.ReplaceAll(layout.TabNamePattern, configuration.YearPlaceholder, strconv.Itoa(date.Year()))
+}
+
+func GetCategorySheetName(layout configuration.Layout, date time.Time) string {
+	return strings.ReplaceAll(layout.CategoryTabPattern, configuration.YearPlaceholder, strconv.Itoa(date.Year()))
 }
 
-func getCurrentMonthColumn() int {
-	// x6 because each month has 6 columns
-	return int(time.Now().Month()) * ColumnPerMonth
+// getMonthColumn returns the zero-based column where the date's month block starts
+func getMonthColumn(layout configuration.Layout, date time.Time) int {
+	firstColumn := convertFromXlsxColumn(layout.FirstMonthColumn) - 1
+	return firstColumn + (int(date.Month())-1)*layout.ColumnsPerMonth
 }
 
-func GetCurrentDayRow() int {
-	// +2 because the first and second rows are reserved for the header
-	return time.Now().Day() + 2
+func GetDayRow(layout configuration.Layout, date time.Time) int {
+	// the first rows are reserved for the header
+	return date.Day() + layout.HeaderRows
 }
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitortenor/sheet-bot/internal/configuration"
+)
+
+func TestParseMoneyValue(t *testing.T) {
+
+	_ = t.Run("formatted value", func(t *testing.T) {
+		// act
+		value, err := ParseMoneyValue("R$ 1.234,56")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, 1234.56, value)
+	})
+
+	_ = t.Run("negative value", func(t *testing.T) {
+		// act
+		value, err := ParseMoneyValue("-R$ 10,00")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Equal(t, -10.0, value)
+	})
+
+	_ = t.Run("empty value", func(t *testing.T) {
+		// act
+		value, err := ParseMoneyValue("")
+
+		// assert
+		_ = assert.NoError(t, err)
+		_ = assert.Zero(t, value)
+	})
+}
+
+func TestFormatMoney(t *testing.T) {
+
+	_ = t.Run("thousands", func(t *testing.T) {
+		// act
+		formatted := FormatMoney(1234567.8)
+
+		// assert
+		_ = assert.Equal(t, "R$ 1.234.567,80", formatted)
+	})
+
+	_ = t.Run("negative", func(t *testing.T) {
+		// act
+		formatted := FormatMoney(-25)
+
+		// assert
+		_ = assert.Equal(t, "-R$ 25,00", formatted)
+	})
+}
+
+func TestXlsxColumns(t *testing.T) {
+
+	_ = t.Run("round trip", func(t *testing.T) {
+		for _, column := range []int{1, 26, 27, 72, 703} {
+			// act
+			title := convertToXlsxColumn(column)
+
+			// assert
+			_ = assert.Equal(t, column, convertFromXlsxColumn(title))
+		}
+	})
+}
+
+func TestBuildRanges(t *testing.T) {
+
+	_ = t.Run("explicit date", func(t *testing.T) {
+		// arrange
+		layout := configuration.DefaultLayout()
+		layout.CategoryTabPattern = "{year} categorias"
+		date := time.Date(2025, 3, 12, 0, 0, 0, 0, time.Local)
+
+		// act
+		dailyOutcomeRange := BuildDailyOutcomeRange(layout, date)
+		incomeRange := BuildIncomeRange(layout, date)
+		categoryRange := BuildCategoryRange(layout, date, 4)
+
+		// assert
+		_ = assert.Equal(t, "'2025'!P14", dailyOutcomeRange)
+		_ = assert.Equal(t, "'2025'!N14", incomeRange)
+		_ = assert.Equal(t, "'2025 categorias'!D4", categoryRange)
+	})
+}
+
+// the default layout must keep addressing the cells the bot used before the layout was configurable
+func TestBuildRanges_DefaultLayout(t *testing.T) {
+	tests := []struct {
+		date         time.Time
+		income       string
+		dailyOutcome string
+		balance      string
+		monthDaily   string
+	}{
+		{time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local), "'2025'!B3", "'2025'!D3", "'2025'!E3", "'2025'!D3:D33"},
+		{time.Date(2025, 3, 12, 0, 0, 0, 0, time.Local), "'2025'!N14", "'2025'!P14", "'2025'!Q14", "'2025'!P3:P33"},
+		{time.Date(2025, 2, 10, 0, 0, 0, 0, time.Local), "'2025'!H12", "'2025'!J12", "'2025'!K12", "'2025'!J3:J30"},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local), "'2024'!H31", "'2024'!J31", "'2024'!K31", "'2024'!J3:J31"},
+		{time.Date(2025, 4, 30, 0, 0, 0, 0, time.Local), "'2025'!T32", "'2025'!V32", "'2025'!W32", "'2025'!V3:V32"},
+		{time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local), "'2026'!BP33", "'2026'!BR33", "'2026'!BS33", "'2026'!BR3:BR33"},
+	}
+
+	for _, test := range tests {
+		_ = t.Run(test.date.Format("2006-01-02"), func(t *testing.T) {
+			// arrange
+			layout := configuration.DefaultLayout()
+
+			// act
+			income := BuildIncomeRange(layout, test.date)
+			dailyOutcome := BuildDailyOutcomeRange(layout, test.date)
+			balance := BuildBalanceRange(layout, test.date)
+			monthDaily := BuildMonthDailyOutcomeRange(layout, test.date)
+
+			// assert
+			_ = assert.Equal(t, test.income, income)
+			_ = assert.Equal(t, test.dailyOutcome, dailyOutcome)
+			_ = assert.Equal(t, test.balance, balance)
+			_ = assert.Equal(t, test.monthDaily, monthDaily)
+			_ = assert.Equal(t, test.date.Day()+2, GetDayRow(layout, test.date))
+		})
+	}
+}